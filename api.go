@@ -28,11 +28,75 @@ type apiParams struct {
 	version int
 	admin   bool // = Flag to mark if API requires admin header
 
+	// idempotent marks read-only commands, that can safely be
+	// retried.
+	idempotent bool
+
 	method string
 	query  url.Values
 	body   []byte
 }
 
+// commandParams prepares a POST call to the given version of ejabberd
+// command name, with args encoded as JSON body. Each typed command
+// pins the version whose arguments and result it has been written
+// for, as commands may change between API versions.
+func commandParams(name string, version int, args interface{}) (apiParams, error) {
+	body, err := json.Marshal(args)
+	if err != nil {
+		return apiParams{}, err
+	}
+
+	return apiParams{
+		name:    name,
+		version: version,
+
+		method: "POST",
+		body:   body,
+	}, nil
+}
+
+// decodeResponse decodes JSON body returned by command name into v.
+func decodeResponse(name string, body []byte, v interface{}) error {
+	if err := json.Unmarshal(body, v); err != nil {
+		return DecodeError{Command: name, Body: body, Err: err}
+	}
+	return nil
+}
+
+// parseResCode decodes the result of commands returning a result
+// code: 0 on success and 1 on failure.
+func parseResCode(name string, body []byte) (bool, error) {
+	var code int
+	if err := decodeResponse(name, body, &code); err != nil {
+		return false, err
+	}
+	return code == 0, nil
+}
+
+// parseAction decodes the result code of commands performing an
+// action. Failure is reported as an APIError.
+func parseAction(name string, body []byte) (Response, error) {
+	ok, err := parseResCode(name, body)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, APIError{Status: "error", Code: 1, Message: name + " failed", HTTPStatus: 200, Command: name, Body: body}
+	}
+	return Message(""), nil
+}
+
+// Message is the text returned by ejabberd commands reporting the
+// outcome of an action.
+type Message string
+
+// JSON represents Message as a JSON string.
+func (m Message) JSON() string {
+	body, _ := json.Marshal(m)
+	return string(body)
+}
+
 //==============================================================================
 
 // TODO: Move into a api_stats file
@@ -85,10 +149,11 @@ func (s statsRequest) paramsStats() (apiParams, error) {
 		name:    "stats",
 		version: 1,
 
-		admin:  true,
-		method: "POST",
-		query:  query,
-		body:   body,
+		admin:      true,
+		idempotent: true,
+		method:     "POST",
+		query:      query,
+		body:       body,
 	}, nil
 }
 
@@ -96,7 +161,7 @@ func (s statsRequest) parseResponse(body []byte) (Response, error) {
 	var resp Stats
 	err := json.Unmarshal(body, &resp)
 	if err != nil {
-		return resp, APIError{Code: 99, Message: err.Error()}
+		return resp, DecodeError{Command: "stats", Body: body, Err: err}
 	}
 	resp.Name = s.Name
 	return resp, err
@@ -164,7 +229,7 @@ func (r registerRequest) parseResponse(body []byte) (Response, error) {
 	var resp Register
 	err := json.Unmarshal(body, &resp)
 	if err != nil {
-		return resp, APIError{Code: 99, Message: err.Error()}
+		return resp, DecodeError{Command: "register", Body: body, Err: err}
 	}
 	return resp, nil
 }
@@ -217,8 +282,9 @@ func (o offlineCountRequest) params() (apiParams, error) {
 	}
 
 	return apiParams{
-		name:    "get_offline_count",
-		version: 1,
+		name:       "get_offline_count",
+		version:    1,
+		idempotent: true,
 
 		method: "POST",
 		query:  query,
@@ -230,7 +296,7 @@ func (o offlineCountRequest) parseResponse(body []byte) (Response, error) {
 	var resp OfflineCount
 	err := json.Unmarshal(body, &resp)
 	if err != nil {
-		return resp, APIError{Code: 99, Message: err.Error()}
+		return resp, DecodeError{Command: "get_offline_count", Body: body, Err: err}
 	}
 	resp.Name = "offline_count"
 	resp.JID = o.JID
@@ -288,8 +354,9 @@ func (u userResourcesRequest) params() (apiParams, error) {
 	}
 
 	return apiParams{
-		name:    "user_resources",
-		version: 1,
+		name:       "user_resources",
+		version:    1,
+		idempotent: true,
 
 		method: "POST",
 		query:  query,
@@ -303,7 +370,7 @@ func (u userResourcesRequest) parseResponse(body []byte) (Response, error) {
 	var data []string
 	err := json.Unmarshal(body, &data)
 	if err != nil {
-		return resp, APIError{Code: 99, Message: err.Error()}
+		return resp, DecodeError{Command: "user_resources", Body: body, Err: err}
 	}
 	resp.JID = u.JID
 	resp.Resources = data
@@ -314,33 +381,3 @@ func (u UserResources) String() string {
 	resources := strings.Join(u.Resources, ",")
 	return fmt.Sprintf("%s", resources)
 }
-
-//==============================================================================
-
-// APIError represents ejabberd error returned by the server as result
-// of ejabberd API calls.
-type APIError struct {
-	Status  string `json:"status"`
-	Code    int    `json:"code"`
-	Message string `json:"message"`
-}
-
-func parseError(body []byte) (APIError, error) {
-	var resp APIError
-	err := json.Unmarshal(body, &resp)
-	if err != nil {
-		return resp, APIError{Code: 99, Message: err.Error()}
-	}
-	return resp, nil
-}
-
-// JSON represents ejabberd error response as a JSON string, for further
-// processing with other tools.
-func (e APIError) JSON() string {
-	body, _ := json.Marshal(e)
-	return string(body)
-}
-
-func (e APIError) Error() string {
-	return fmt.Sprintf("Error %d: %s", e.Code, e.Message)
-}
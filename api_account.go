@@ -0,0 +1,285 @@
+package ejabberd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// Account lifecycle commands, from ejabberd_admin.
+
+//==============================================================================
+
+type unregisterRequest struct {
+	JID string `json:"jid"`
+}
+
+func (u unregisterRequest) params() (apiParams, error) {
+	jid, err := parseJID(u.JID)
+	if err != nil {
+		return apiParams{}, err
+	}
+
+	type unregister struct {
+		User string `json:"user"`
+		Host string `json:"host"`
+	}
+
+	return commandParams("unregister", 1, unregister{
+		User: jid.username,
+		Host: jid.domain,
+	})
+}
+
+func (u unregisterRequest) parseResponse(body []byte) (Response, error) {
+	var resp Message
+	err := decodeResponse("unregister", body, &resp)
+	return resp, err
+}
+
+// UnregisterUser deletes a user account and all its data. It can be
+// called as a user to delete your own account, or as an admin to
+// delete any account.
+func (c Client) UnregisterUser(bareJID string) (Message, error) {
+	return c.UnregisterUserContext(context.Background(), bareJID)
+}
+
+// UnregisterUserContext is like UnregisterUser but carries ctx into
+// the API call.
+func (c Client) UnregisterUserContext(ctx context.Context, bareJID string) (Message, error) {
+	result, err := c.call(ctx, unregisterRequest{JID: bareJID})
+	if err != nil {
+		return "", err
+	}
+	return result.(Message), nil
+}
+
+//==============================================================================
+
+type changePasswordRequest struct {
+	JID         string `json:"jid"`
+	NewPassword string `json:"newpass"`
+}
+
+func (r changePasswordRequest) params() (apiParams, error) {
+	jid, err := parseJID(r.JID)
+	if err != nil {
+		return apiParams{}, err
+	}
+
+	type changePassword struct {
+		User    string `json:"user"`
+		Host    string `json:"host"`
+		NewPass string `json:"newpass"`
+	}
+
+	return commandParams("change_password", 1, changePassword{
+		User:    jid.username,
+		Host:    jid.domain,
+		NewPass: r.NewPassword,
+	})
+}
+
+func (r changePasswordRequest) parseResponse(body []byte) (Response, error) {
+	return parseAction("change_password", body)
+}
+
+// ChangePassword sets a new password for a user. It can be called as
+// a user to change your own password, or as an admin to change the
+// password of any user.
+func (c Client) ChangePassword(bareJID, newPassword string) error {
+	return c.ChangePasswordContext(context.Background(), bareJID, newPassword)
+}
+
+// ChangePasswordContext is like ChangePassword but carries ctx into
+// the API call.
+func (c Client) ChangePasswordContext(ctx context.Context, bareJID, newPassword string) error {
+	_, err := c.call(ctx, changePasswordRequest{JID: bareJID, NewPassword: newPassword})
+	return err
+}
+
+//==============================================================================
+
+// Check contains the result of ejabberd commands checking a user
+// account or credentials.
+type Check struct {
+	Name  string `json:"name"`
+	JID   string `json:"jid"`
+	Value bool   `json:"value"`
+}
+
+// JSON represents Check as a JSON string, for further processing with
+// other tools.
+func (c Check) JSON() string {
+	body, _ := json.Marshal(c)
+	return string(body)
+}
+
+func (c Check) String() string {
+	return fmt.Sprintf("%t", c.Value)
+}
+
+//==============================================================================
+
+type checkAccountRequest struct {
+	JID string `json:"jid"`
+}
+
+func (r checkAccountRequest) params() (apiParams, error) {
+	jid, err := parseJID(r.JID)
+	if err != nil {
+		return apiParams{}, err
+	}
+
+	type checkAccount struct {
+		User string `json:"user"`
+		Host string `json:"host"`
+	}
+
+	p, err := commandParams("check_account", 1, checkAccount{
+		User: jid.username,
+		Host: jid.domain,
+	})
+	p.idempotent = true
+	return p, err
+}
+
+func (r checkAccountRequest) parseResponse(body []byte) (Response, error) {
+	ok, err := parseResCode("check_account", body)
+	if err != nil {
+		return Check{}, err
+	}
+	return Check{Name: "check_account", JID: r.JID, Value: ok}, nil
+}
+
+// CheckAccount tells if a user account exists.
+func (c Client) CheckAccount(bareJID string) (Check, error) {
+	return c.CheckAccountContext(context.Background(), bareJID)
+}
+
+// CheckAccountContext is like CheckAccount but carries ctx into the
+// API call.
+func (c Client) CheckAccountContext(ctx context.Context, bareJID string) (Check, error) {
+	result, err := c.call(ctx, checkAccountRequest{JID: bareJID})
+	if err != nil {
+		return Check{}, err
+	}
+	return result.(Check), nil
+}
+
+//==============================================================================
+
+type checkPasswordRequest struct {
+	JID      string `json:"jid"`
+	Password string `json:"password"`
+}
+
+func (r checkPasswordRequest) params() (apiParams, error) {
+	jid, err := parseJID(r.JID)
+	if err != nil {
+		return apiParams{}, err
+	}
+
+	type checkPassword struct {
+		User     string `json:"user"`
+		Host     string `json:"host"`
+		Password string `json:"password"`
+	}
+
+	p, err := commandParams("check_password", 1, checkPassword{
+		User:     jid.username,
+		Host:     jid.domain,
+		Password: r.Password,
+	})
+	p.idempotent = true
+	return p, err
+}
+
+func (r checkPasswordRequest) parseResponse(body []byte) (Response, error) {
+	ok, err := parseResCode("check_password", body)
+	if err != nil {
+		return Check{}, err
+	}
+	return Check{Name: "check_password", JID: r.JID, Value: ok}, nil
+}
+
+// CheckPassword tells if password is the valid password for the user.
+func (c Client) CheckPassword(bareJID, password string) (Check, error) {
+	return c.CheckPasswordContext(context.Background(), bareJID, password)
+}
+
+// CheckPasswordContext is like CheckPassword but carries ctx into the
+// API call.
+func (c Client) CheckPasswordContext(ctx context.Context, bareJID, password string) (Check, error) {
+	result, err := c.call(ctx, checkPasswordRequest{JID: bareJID, Password: password})
+	if err != nil {
+		return Check{}, err
+	}
+	return result.(Check), nil
+}
+
+//==============================================================================
+
+type checkPasswordHashRequest struct {
+	JID          string `json:"jid"`
+	PasswordHash string `json:"passwordhash"`
+	HashMethod   string `json:"hashmethod"`
+}
+
+func (r checkPasswordHashRequest) params() (apiParams, error) {
+	jid, err := parseJID(r.JID)
+	if err != nil {
+		return apiParams{}, err
+	}
+
+	if r.HashMethod == "" {
+		return apiParams{}, fmt.Errorf("required argument 'hashmethod' not provided")
+	}
+
+	type checkPasswordHash struct {
+		User         string `json:"user"`
+		Host         string `json:"host"`
+		PasswordHash string `json:"passwordhash"`
+		HashMethod   string `json:"hashmethod"`
+	}
+
+	p, err := commandParams("check_password_hash", 1, checkPasswordHash{
+		User:         jid.username,
+		Host:         jid.domain,
+		PasswordHash: r.PasswordHash,
+		HashMethod:   r.HashMethod,
+	})
+	p.idempotent = true
+	return p, err
+}
+
+func (r checkPasswordHashRequest) parseResponse(body []byte) (Response, error) {
+	ok, err := parseResCode("check_password_hash", body)
+	if err != nil {
+		return Check{}, err
+	}
+	return Check{Name: "check_password_hash", JID: r.JID, Value: ok}, nil
+}
+
+// CheckPasswordHash tells if passwordHash is the hash of the user
+// password, computed with hashMethod, for example md5 or sha. The list
+// of supported hash methods depends on the ejabberd server.
+func (c Client) CheckPasswordHash(bareJID, passwordHash, hashMethod string) (Check, error) {
+	return c.CheckPasswordHashContext(context.Background(), bareJID, passwordHash, hashMethod)
+}
+
+// CheckPasswordHashContext is like CheckPasswordHash but carries ctx
+// into the API call.
+func (c Client) CheckPasswordHashContext(ctx context.Context, bareJID, passwordHash, hashMethod string) (Check, error) {
+	command := checkPasswordHashRequest{
+		JID:          bareJID,
+		PasswordHash: passwordHash,
+		HashMethod:   hashMethod,
+	}
+
+	result, err := c.call(ctx, command)
+	if err != nil {
+		return Check{}, err
+	}
+	return result.(Check), nil
+}
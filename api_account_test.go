@@ -0,0 +1,69 @@
+package ejabberd_test
+
+import (
+	"testing"
+
+	"github.com/processone/ejabberd-api"
+)
+
+func Test_AccountLifecycle(t *testing.T) {
+	server, calls := newAPIServer(t, func(call apiCall) (int, string) {
+		switch call.command {
+		case "unregister":
+			return 200, `""`
+		case "check_account":
+			return 200, `1`
+		case "check_password":
+			return 200, `0`
+		}
+		return 200, `1`
+	})
+	defer server.Close()
+
+	token := ejabberd.OAuthToken{JID: "admin@localhost"}
+	client := ejabberd.Client{BaseURL: server.URL, Token: token}
+
+	if _, err := client.UnregisterUser("test@localhost"); err != nil {
+		t.Errorf("UnregisterUser failed: %s", err)
+	}
+	if check, err := client.CheckAccount("test@localhost"); err != nil || check.Value {
+		t.Errorf("CheckAccount = %v, %v; want false", check, err)
+	}
+	if check, err := client.CheckPassword("admin@localhost", "passw0rd"); err != nil || !check.Value {
+		t.Errorf("CheckPassword = %v, %v; want true", check, err)
+	}
+	if err := client.ChangePassword("test@localhost", "n3w"); err == nil {
+		t.Errorf("ChangePassword should have failed on result code 1")
+	}
+
+	want := []apiCall{
+		{"unregister", true, map[string]interface{}{"user": "test", "host": "localhost"}},
+		{"check_account", true, map[string]interface{}{"user": "test", "host": "localhost"}},
+		{"check_password", false, map[string]interface{}{"user": "admin", "host": "localhost", "password": "passw0rd"}},
+		{"change_password", true, map[string]interface{}{"user": "test", "host": "localhost", "newpass": "n3w"}},
+	}
+	checkCalls(t, *calls, want)
+}
+
+func Test_CheckPasswordHash(t *testing.T) {
+	server, calls := newAPIServer(t, func(call apiCall) (int, string) {
+		return 200, `0`
+	})
+	defer server.Close()
+
+	token := ejabberd.OAuthToken{JID: "admin@localhost"}
+	client := ejabberd.Client{BaseURL: server.URL, Token: token}
+
+	// Hash methods are checked by the server.
+	if check, err := client.CheckPasswordHash("admin@localhost", "5e88489", "sha256"); err != nil || !check.Value {
+		t.Errorf("CheckPasswordHash = %v, %v; want true", check, err)
+	}
+	if _, err := client.CheckPasswordHash("admin@localhost", "5e88489", ""); err == nil {
+		t.Errorf("CheckPasswordHash should have failed on empty hash method")
+	}
+
+	want := []apiCall{
+		{"check_password_hash", false, map[string]interface{}{"user": "admin", "host": "localhost", "passwordhash": "5e88489", "hashmethod": "sha256"}},
+	}
+	checkCalls(t, *calls, want)
+}
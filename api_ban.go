@@ -0,0 +1,194 @@
+package ejabberd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Account ban commands, from mod_admin_extra.
+
+//==============================================================================
+
+type banAccountRequest struct {
+	JID    string `json:"jid"`
+	Reason string `json:"reason"`
+}
+
+func (b banAccountRequest) params() (apiParams, error) {
+	jid, err := parseJID(b.JID)
+	if err != nil {
+		return apiParams{}, err
+	}
+
+	type banAccount struct {
+		User   string `json:"user"`
+		Host   string `json:"host"`
+		Reason string `json:"reason"`
+	}
+
+	p, err := commandParams("ban_account", 2, banAccount{
+		User:   jid.username,
+		Host:   jid.domain,
+		Reason: b.Reason,
+	})
+	p.admin = true
+	return p, err
+}
+
+func (b banAccountRequest) parseResponse(body []byte) (Response, error) {
+	return parseAction("ban_account", body)
+}
+
+// BanAccount bans a user account: its sessions are closed and it
+// cannot log in anymore, until the ban is reverted with UnbanAccount.
+// The account password is kept. It requires admin rights and ejabberd
+// 24.06 or later, as it calls version 2 of ban_account command.
+func (c Client) BanAccount(bareJID, reason string) error {
+	return c.BanAccountContext(context.Background(), bareJID, reason)
+}
+
+// BanAccountContext is like BanAccount but carries ctx into the API
+// call.
+func (c Client) BanAccountContext(ctx context.Context, bareJID, reason string) error {
+	_, err := c.call(ctx, banAccountRequest{JID: bareJID, Reason: reason})
+	return err
+}
+
+//==============================================================================
+
+type unbanAccountRequest struct {
+	JID string `json:"jid"`
+}
+
+func (u unbanAccountRequest) params() (apiParams, error) {
+	jid, err := parseJID(u.JID)
+	if err != nil {
+		return apiParams{}, err
+	}
+
+	type unbanAccount struct {
+		User string `json:"user"`
+		Host string `json:"host"`
+	}
+
+	p, err := commandParams("unban_account", 2, unbanAccount{
+		User: jid.username,
+		Host: jid.domain,
+	})
+	p.admin = true
+	return p, err
+}
+
+func (u unbanAccountRequest) parseResponse(body []byte) (Response, error) {
+	return parseAction("unban_account", body)
+}
+
+// UnbanAccount reverts a ban set with BanAccount, so that the user can
+// log in again. It requires admin rights and ejabberd 24.06 or later.
+func (c Client) UnbanAccount(bareJID string) error {
+	return c.UnbanAccountContext(context.Background(), bareJID)
+}
+
+// UnbanAccountContext is like UnbanAccount but carries ctx into the
+// API call.
+func (c Client) UnbanAccountContext(ctx context.Context, bareJID string) error {
+	_, err := c.call(ctx, unbanAccountRequest{JID: bareJID})
+	return err
+}
+
+//==============================================================================
+
+// BanDetails contains the result of the call to ejabberd
+// get_ban_details API. Banned is false, and other fields are empty,
+// when the account is not banned.
+type BanDetails struct {
+	JID          string    `json:"jid"`
+	Banned       bool      `json:"banned"`
+	Reason       string    `json:"reason,omitempty"`
+	BanDate      time.Time `json:"ban_date"`
+	LastActivity time.Time `json:"last_activity"`
+	LastReason   string    `json:"last_reason,omitempty"`
+}
+
+// JSON represents BanDetails as a JSON string, for further processing
+// with other tools.
+func (b BanDetails) JSON() string {
+	body, _ := json.Marshal(b)
+	return string(body)
+}
+
+func (b BanDetails) String() string {
+	if !b.Banned {
+		return fmt.Sprintf("%s is not banned", b.JID)
+	}
+	return fmt.Sprintf("%s banned on %s: %s", b.JID, b.BanDate.Format(time.RFC3339), b.Reason)
+}
+
+type banDetailsRequest struct {
+	JID string `json:"jid"`
+}
+
+func (b banDetailsRequest) params() (apiParams, error) {
+	jid, err := parseJID(b.JID)
+	if err != nil {
+		return apiParams{}, err
+	}
+
+	type banDetails struct {
+		User string `json:"user"`
+		Host string `json:"host"`
+	}
+
+	p, err := commandParams("get_ban_details", 2, banDetails{
+		User: jid.username,
+		Host: jid.domain,
+	})
+	p.admin = true
+	p.idempotent = true
+	return p, err
+}
+
+func (b banDetailsRequest) parseResponse(body []byte) (Response, error) {
+	// Details are returned as a list of name / value pairs.
+	var data []struct {
+		Name  string `json:"name"`
+		Value string `json:"value"`
+	}
+	if err := decodeResponse("get_ban_details", body, &data); err != nil {
+		return BanDetails{}, err
+	}
+
+	resp := BanDetails{JID: b.JID, Banned: len(data) > 0}
+	for _, detail := range data {
+		switch detail.Name {
+		case "reason":
+			resp.Reason = detail.Value
+		case "bandate":
+			resp.BanDate = parseTimestamp(detail.Value)
+		case "lastdate":
+			resp.LastActivity = parseTimestamp(detail.Value)
+		case "lastreason":
+			resp.LastReason = detail.Value
+		}
+	}
+	return resp, nil
+}
+
+// GetBanDetails returns ban status and details of a user account. It
+// requires admin rights and ejabberd 24.06 or later.
+func (c Client) GetBanDetails(bareJID string) (BanDetails, error) {
+	return c.GetBanDetailsContext(context.Background(), bareJID)
+}
+
+// GetBanDetailsContext is like GetBanDetails but carries ctx into the
+// API call.
+func (c Client) GetBanDetailsContext(ctx context.Context, bareJID string) (BanDetails, error) {
+	result, err := c.call(ctx, banDetailsRequest{JID: bareJID})
+	if err != nil {
+		return BanDetails{}, err
+	}
+	resp := result.(BanDetails)
+	return resp, nil
+}
@@ -0,0 +1,92 @@
+package ejabberd_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/processone/ejabberd-api"
+)
+
+func Test_GetBanDetails(t *testing.T) {
+	server, _ := newAPIServer(t, func(call apiCall) (int, string) {
+		if call.args["user"] == "spammer" {
+			return 200, `[{"name":"reason","value":"Spam"},
+			              {"name":"bandate","value":"2024-05-13T15:32:02.124Z"},
+			              {"name":"lastdate","value":"2024-05-13T15:30:00Z"},
+			              {"name":"lastreason","value":"Connection closed"}]`
+		}
+		return 200, `[]`
+	})
+	defer server.Close()
+
+	client := ejabberd.Client{BaseURL: server.URL}
+
+	details, err := client.GetBanDetails("spammer@localhost")
+	if err != nil {
+		t.Fatalf("GetBanDetails failed: %s", err)
+	}
+	banDate := time.Date(2024, 5, 13, 15, 32, 2, 124000000, time.UTC)
+	if !details.Banned || details.Reason != "Spam" || !details.BanDate.Equal(banDate) || details.LastReason != "Connection closed" {
+		t.Errorf("Incorrect ban details %+v", details)
+	}
+
+	details, err = client.GetBanDetails("alice@localhost")
+	if err != nil || details.Banned {
+		t.Errorf("GetBanDetails = %+v, %v; want not banned", details, err)
+	}
+}
+
+func Test_BanSequence(t *testing.T) {
+	var paths []string
+	banned := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		switch r.URL.Path {
+		case "/api/ban_account/v2":
+			banned = true
+			fmt.Fprint(w, `0`)
+		case "/api/unban_account/v2":
+			banned = false
+			fmt.Fprint(w, `0`)
+		case "/api/get_ban_details/v2":
+			if banned {
+				fmt.Fprint(w, `[{"name":"reason","value":"Spam"}]`)
+			} else {
+				fmt.Fprint(w, `[]`)
+			}
+		default:
+			w.WriteHeader(404)
+		}
+	}))
+	defer server.Close()
+
+	client := ejabberd.Client{BaseURL: server.URL}
+
+	if err := client.BanAccount("spammer@localhost", "Spam"); err != nil {
+		t.Fatalf("BanAccount failed: %s", err)
+	}
+	details, err := client.GetBanDetails("spammer@localhost")
+	if err != nil || !details.Banned || details.Reason != "Spam" {
+		t.Errorf("GetBanDetails = %+v, %v; want banned for Spam", details, err)
+	}
+	if err := client.UnbanAccount("spammer@localhost"); err != nil {
+		t.Fatalf("UnbanAccount failed: %s", err)
+	}
+	details, err = client.GetBanDetails("spammer@localhost")
+	if err != nil || details.Banned {
+		t.Errorf("GetBanDetails = %+v, %v; want not banned", details, err)
+	}
+
+	want := []string{
+		"/api/ban_account/v2",
+		"/api/get_ban_details/v2",
+		"/api/unban_account/v2",
+		"/api/get_ban_details/v2",
+	}
+	if fmt.Sprint(paths) != fmt.Sprint(want) {
+		t.Errorf("Incorrect request paths %v, want %v", paths, want)
+	}
+}
@@ -0,0 +1,266 @@
+package ejabberd
+
+import (
+	"context"
+	"fmt"
+)
+
+// Message archive (MAM) maintenance commands, from mod_mam.
+
+//==============================================================================
+
+// checkMAMType checks the type of archived messages to remove: chat,
+// groupchat or all. Empty type defaults to all.
+func checkMAMType(t string) (string, error) {
+	switch t {
+	case "":
+		return "all", nil
+	case "all", "chat", "groupchat":
+		return t, nil
+	}
+	return "", fmt.Errorf("unknown message type: %s", t)
+}
+
+//==============================================================================
+
+type deleteOldMAMMessagesRequest struct {
+	Type string `json:"type"`
+	Days int    `json:"days"`
+}
+
+func (d deleteOldMAMMessagesRequest) params() (apiParams, error) {
+	t, err := checkMAMType(d.Type)
+	if err != nil {
+		return apiParams{}, err
+	}
+	if d.Days <= 0 {
+		return apiParams{}, fmt.Errorf("days must be positive: %d", d.Days)
+	}
+
+	p, err := commandParams("delete_old_mam_messages", 1, deleteOldMAMMessagesRequest{Type: t, Days: d.Days})
+	p.admin = true
+	return p, err
+}
+
+func (d deleteOldMAMMessagesRequest) parseResponse(body []byte) (Response, error) {
+	return parseAction("delete_old_mam_messages", body)
+}
+
+// DeleteOldMAMMessages removes from the archives of all users the
+// messages older than the given number of days. msgType is chat,
+// groupchat or all. It requires admin rights.
+//
+// Removal is done in a single operation, which can be slow on large
+// archives. Use DeleteOldMAMMessagesBatch to spread the load.
+func (c Client) DeleteOldMAMMessages(msgType string, days int) error {
+	return c.DeleteOldMAMMessagesContext(context.Background(), msgType, days)
+}
+
+// DeleteOldMAMMessagesContext is like DeleteOldMAMMessages but carries
+// ctx into the API call.
+func (c Client) DeleteOldMAMMessagesContext(ctx context.Context, msgType string, days int) error {
+	_, err := c.call(ctx, deleteOldMAMMessagesRequest{Type: msgType, Days: days})
+	return err
+}
+
+//==============================================================================
+
+// MAMBatch describes a removal of old archived messages, performed in
+// batches by the server.
+type MAMBatch struct {
+	Host      string `json:"host"`
+	Type      string `json:"type"` // chat, groupchat or all. Defaults to all.
+	Days      int    `json:"days"`
+	BatchSize int    `json:"batch_size"` // Number of messages removed per batch.
+	Rate      int    `json:"rate"`       // Maximum number of messages removed per minute.
+}
+
+type deleteOldMAMMessagesBatchRequest struct {
+	Batch MAMBatch `json:"batch"`
+}
+
+func (d deleteOldMAMMessagesBatchRequest) params() (apiParams, error) {
+	b := d.Batch
+	if b.Host == "" {
+		return apiParams{}, fmt.Errorf("required argument 'host' not provided")
+	}
+	t, err := checkMAMType(b.Type)
+	if err != nil {
+		return apiParams{}, err
+	}
+	b.Type = t
+	if b.Days <= 0 {
+		return apiParams{}, fmt.Errorf("days must be positive: %d", b.Days)
+	}
+	if b.BatchSize <= 0 || b.Rate <= 0 {
+		return apiParams{}, fmt.Errorf("batch size and rate must be positive")
+	}
+
+	p, err := commandParams("delete_old_mam_messages_batch", 1, b)
+	p.admin = true
+	return p, err
+}
+
+func (d deleteOldMAMMessagesBatchRequest) parseResponse(body []byte) (Response, error) {
+	var resp Message
+	err := decodeResponse("delete_old_mam_messages_batch", body, &resp)
+	return resp, err
+}
+
+// DeleteOldMAMMessagesBatch starts the removal, on a virtual host, of
+// the archived messages older than batch.Days. The server removes
+// them in the background, batch.BatchSize messages at a time, and at
+// most batch.Rate messages per minute. Progress is reported by
+// DeleteOldMAMMessagesStatus. It requires admin rights.
+func (c Client) DeleteOldMAMMessagesBatch(batch MAMBatch) (Message, error) {
+	return c.DeleteOldMAMMessagesBatchContext(context.Background(), batch)
+}
+
+// DeleteOldMAMMessagesBatchContext is like DeleteOldMAMMessagesBatch
+// but carries ctx into the API call.
+func (c Client) DeleteOldMAMMessagesBatchContext(ctx context.Context, batch MAMBatch) (Message, error) {
+	result, err := c.call(ctx, deleteOldMAMMessagesBatchRequest{Batch: batch})
+	if err != nil {
+		return "", err
+	}
+	return result.(Message), nil
+}
+
+//==============================================================================
+
+type mamBatchHostRequest struct {
+	Name string `json:"-"`
+	Host string `json:"host"`
+}
+
+func (m mamBatchHostRequest) params() (apiParams, error) {
+	if m.Host == "" {
+		return apiParams{}, fmt.Errorf("required argument 'host' not provided")
+	}
+
+	p, err := commandParams(m.Name, 1, m)
+	p.admin = true
+	p.idempotent = m.Name == "delete_old_mam_messages_status"
+	return p, err
+}
+
+func (m mamBatchHostRequest) parseResponse(body []byte) (Response, error) {
+	var resp Message
+	err := decodeResponse(m.Name, body, &resp)
+	return resp, err
+}
+
+// DeleteOldMAMMessagesStatus reports the progress of the removal
+// started by DeleteOldMAMMessagesBatch on a virtual host. It requires
+// admin rights.
+func (c Client) DeleteOldMAMMessagesStatus(host string) (Message, error) {
+	return c.DeleteOldMAMMessagesStatusContext(context.Background(), host)
+}
+
+// DeleteOldMAMMessagesStatusContext is like DeleteOldMAMMessagesStatus
+// but carries ctx into the API call.
+func (c Client) DeleteOldMAMMessagesStatusContext(ctx context.Context, host string) (Message, error) {
+	result, err := c.call(ctx, mamBatchHostRequest{Name: "delete_old_mam_messages_status", Host: host})
+	if err != nil {
+		return "", err
+	}
+	return result.(Message), nil
+}
+
+// AbortDeleteOldMAMMessages stops the removal started by
+// DeleteOldMAMMessagesBatch on a virtual host. It requires admin
+// rights.
+func (c Client) AbortDeleteOldMAMMessages(host string) (Message, error) {
+	return c.AbortDeleteOldMAMMessagesContext(context.Background(), host)
+}
+
+// AbortDeleteOldMAMMessagesContext is like AbortDeleteOldMAMMessages
+// but carries ctx into the API call.
+func (c Client) AbortDeleteOldMAMMessagesContext(ctx context.Context, host string) (Message, error) {
+	result, err := c.call(ctx, mamBatchHostRequest{Name: "abort_delete_old_mam_messages", Host: host})
+	if err != nil {
+		return "", err
+	}
+	return result.(Message), nil
+}
+
+//==============================================================================
+
+type removeMAMForUserRequest struct {
+	JID  string `json:"jid"`
+	Peer string `json:"peer"`
+}
+
+func (r removeMAMForUserRequest) command() string {
+	if r.Peer == "" {
+		return "remove_mam_for_user"
+	}
+	return "remove_mam_for_user_with_peer"
+}
+
+func (r removeMAMForUserRequest) params() (apiParams, error) {
+	jid, err := parseJID(r.JID)
+	if err != nil {
+		return apiParams{}, err
+	}
+	if r.Peer != "" {
+		if err := checkJID(r.Peer); err != nil {
+			return apiParams{}, err
+		}
+	}
+
+	type removeMAMForUser struct {
+		User   string `json:"user"`
+		Server string `json:"server"`
+		With   string `json:"with,omitempty"`
+	}
+
+	p, err := commandParams(r.command(), 1, removeMAMForUser{
+		User:   jid.username,
+		Server: jid.domain,
+		With:   r.Peer,
+	})
+	p.admin = true
+	return p, err
+}
+
+func (r removeMAMForUserRequest) parseResponse(body []byte) (Response, error) {
+	var resp Message
+	err := decodeResponse(r.command(), body, &resp)
+	return resp, err
+}
+
+// RemoveMAMForUser removes the whole message archive of a user. It
+// requires admin rights.
+func (c Client) RemoveMAMForUser(bareJID string) (Message, error) {
+	return c.RemoveMAMForUserContext(context.Background(), bareJID)
+}
+
+// RemoveMAMForUserContext is like RemoveMAMForUser but carries ctx
+// into the API call.
+func (c Client) RemoveMAMForUserContext(ctx context.Context, bareJID string) (Message, error) {
+	result, err := c.call(ctx, removeMAMForUserRequest{JID: bareJID})
+	if err != nil {
+		return "", err
+	}
+	return result.(Message), nil
+}
+
+// RemoveMAMForUserWithPeer removes the messages exchanged between a
+// user and peer from the user archive. It requires admin rights.
+func (c Client) RemoveMAMForUserWithPeer(bareJID, peer string) (Message, error) {
+	return c.RemoveMAMForUserWithPeerContext(context.Background(), bareJID, peer)
+}
+
+// RemoveMAMForUserWithPeerContext is like RemoveMAMForUserWithPeer but
+// carries ctx into the API call.
+func (c Client) RemoveMAMForUserWithPeerContext(ctx context.Context, bareJID, peer string) (Message, error) {
+	if peer == "" {
+		return "", fmt.Errorf("required argument 'peer' not provided")
+	}
+	result, err := c.call(ctx, removeMAMForUserRequest{JID: bareJID, Peer: peer})
+	if err != nil {
+		return "", err
+	}
+	return result.(Message), nil
+}
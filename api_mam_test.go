@@ -0,0 +1,70 @@
+package ejabberd_test
+
+import (
+	"testing"
+
+	"github.com/processone/ejabberd-api"
+)
+
+func Test_DeleteOldMAMMessagesBatch(t *testing.T) {
+	server, calls := newAPIServer(t, func(call apiCall) (int, string) {
+		if call.command == "delete_old_mam_messages_status" {
+			return 200, `"Operation in progress, removed 1000 messages"`
+		}
+		return 200, `"Removal of messages in progress"`
+	})
+	defer server.Close()
+
+	client := ejabberd.Client{BaseURL: server.URL}
+
+	var tests = []ejabberd.MAMBatch{
+		{Days: 30, BatchSize: 100, Rate: 1000},
+		{Host: "localhost", Type: "headline", Days: 30, BatchSize: 100, Rate: 1000},
+		{Host: "localhost", BatchSize: 100, Rate: 1000},
+		{Host: "localhost", Days: 30, Rate: 1000},
+	}
+	for _, test := range tests {
+		if _, err := client.DeleteOldMAMMessagesBatch(test); err == nil {
+			t.Errorf("DeleteOldMAMMessagesBatch accepted %+v", test)
+		}
+	}
+
+	_, err := client.DeleteOldMAMMessagesBatch(ejabberd.MAMBatch{Host: "localhost", Days: 30, BatchSize: 100, Rate: 1000})
+	if err != nil {
+		t.Fatalf("DeleteOldMAMMessagesBatch failed: %s", err)
+	}
+	status, err := client.DeleteOldMAMMessagesStatus("localhost")
+	if err != nil || status != "Operation in progress, removed 1000 messages" {
+		t.Errorf("DeleteOldMAMMessagesStatus = %q, %v", status, err)
+	}
+
+	checkCalls(t, *calls, []apiCall{
+		{command: "delete_old_mam_messages_batch", admin: true, args: map[string]interface{}{
+			"host": "localhost", "type": "all", "days": float64(30), "batch_size": float64(100), "rate": float64(1000),
+		}},
+		{command: "delete_old_mam_messages_status", admin: true, args: map[string]interface{}{"host": "localhost"}},
+	})
+}
+
+func Test_RemoveMAMForUser(t *testing.T) {
+	server, calls := newAPIServer(t, func(call apiCall) (int, string) {
+		return 200, `"MAM archive removed"`
+	})
+	defer server.Close()
+
+	client := ejabberd.Client{BaseURL: server.URL}
+
+	if _, err := client.RemoveMAMForUser("alice@localhost"); err != nil {
+		t.Fatalf("RemoveMAMForUser failed: %s", err)
+	}
+	if _, err := client.RemoveMAMForUserWithPeer("alice@localhost", "bob@localhost"); err != nil {
+		t.Fatalf("RemoveMAMForUserWithPeer failed: %s", err)
+	}
+
+	checkCalls(t, *calls, []apiCall{
+		{command: "remove_mam_for_user", admin: true, args: map[string]interface{}{"user": "alice", "server": "localhost"}},
+		{command: "remove_mam_for_user_with_peer", admin: true, args: map[string]interface{}{
+			"user": "alice", "server": "localhost", "with": "bob@localhost",
+		}},
+	})
+}
@@ -0,0 +1,705 @@
+package ejabberd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Multi-user chat room commands, from mod_muc_admin.
+
+//==============================================================================
+
+// RoomOptions contains the configuration of a MUC room. Fields map to
+// ejabberd option names, through their JSON tag. Nil fields are left
+// to the server defaults when creating a room. Options unknown to
+// this library are kept in Other.
+type RoomOptions struct {
+	Title                  *string `json:"title,omitempty"`
+	Description            *string `json:"description,omitempty"`
+	Lang                   *string `json:"lang,omitempty"`
+	Password               *string `json:"password,omitempty"`
+	PasswordProtected      *bool   `json:"password_protected,omitempty"`
+	Persistent             *bool   `json:"persistent,omitempty"`
+	Public                 *bool   `json:"public,omitempty"`
+	PublicList             *bool   `json:"public_list,omitempty"`
+	MembersOnly            *bool   `json:"members_only,omitempty"`
+	MembersByDefault       *bool   `json:"members_by_default,omitempty"`
+	Moderated              *bool   `json:"moderated,omitempty"`
+	Anonymous              *bool   `json:"anonymous,omitempty"`
+	Logging                *bool   `json:"logging,omitempty"`
+	MAM                    *bool   `json:"mam,omitempty"`
+	CaptchaProtected       *bool   `json:"captcha_protected,omitempty"`
+	AllowChangeSubject     *bool   `json:"allow_change_subj,omitempty"`
+	AllowQueryUsers        *bool   `json:"allow_query_users,omitempty"`
+	AllowPrivateMessages   *bool   `json:"allow_private_messages,omitempty"`
+	AllowUserInvites       *bool   `json:"allow_user_invites,omitempty"`
+	AllowSubscription      *bool   `json:"allow_subscription,omitempty"`
+	AllowVisitorStatus     *bool   `json:"allow_visitor_status,omitempty"`
+	AllowVisitorNickChange *bool   `json:"allow_visitor_nickchange,omitempty"`
+	AllowVoiceRequests     *bool   `json:"allow_voice_requests,omitempty"`
+	MaxUsers               *int    `json:"max_users,omitempty"`
+
+	Other map[string]string `json:"other,omitempty"`
+}
+
+// RoomBool returns a pointer to v, to set RoomOptions fields.
+func RoomBool(v bool) *bool { return &v }
+
+// RoomInt returns a pointer to v, to set RoomOptions fields.
+func RoomInt(v int) *int { return &v }
+
+// RoomString returns a pointer to v, to set RoomOptions fields.
+func RoomString(v string) *string { return &v }
+
+// roomOptionFields maps ejabberd option names to RoomOptions field
+// indexes.
+var roomOptionFields = func() map[string]int {
+	fields := make(map[string]int)
+	t := reflect.TypeOf(RoomOptions{})
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.Type.Kind() != reflect.Ptr {
+			continue
+		}
+		fields[strings.Split(f.Tag.Get("json"), ",")[0]] = i
+	}
+	return fields
+}()
+
+// Set sets option name from its ejabberd string representation, like
+// "true" or "50". Unknown options are stored in Other.
+func (o *RoomOptions) Set(name, value string) error {
+	i, ok := roomOptionFields[name]
+	if !ok {
+		if o.Other == nil {
+			o.Other = make(map[string]string)
+		}
+		o.Other[name] = value
+		return nil
+	}
+
+	field := reflect.ValueOf(o).Elem().Field(i)
+	switch field.Type().Elem().Kind() {
+	case reflect.Bool:
+		v, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("invalid value for room option %s: %s", name, value)
+		}
+		field.Set(reflect.ValueOf(&v))
+	case reflect.Int:
+		v, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("invalid value for room option %s: %s", name, value)
+		}
+		field.Set(reflect.ValueOf(&v))
+	default:
+		field.Set(reflect.ValueOf(&value))
+	}
+	return nil
+}
+
+// roomOption is the name / value pair format used by ejabberd for
+// room options.
+type roomOption struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// options returns the options that are set, sorted by name.
+func (o RoomOptions) options() []roomOption {
+	opts := []roomOption{}
+	v := reflect.ValueOf(o)
+	for name, i := range roomOptionFields {
+		field := v.Field(i)
+		if field.IsNil() {
+			continue
+		}
+		opts = append(opts, roomOption{Name: name, Value: fmt.Sprint(field.Elem().Interface())})
+	}
+	for name, value := range o.Other {
+		opts = append(opts, roomOption{Name: name, Value: value})
+	}
+	sort.Slice(opts, func(i, j int) bool { return opts[i].Name < opts[j].Name })
+	return opts
+}
+
+// redact returns a copy of the options with the room password hidden,
+// for display.
+func (o RoomOptions) redact() RoomOptions {
+	if o.Password != nil && *o.Password != "" {
+		o.Password = RoomString(redacted)
+	}
+	return o
+}
+
+// JSON represents RoomOptions as a JSON string, for further processing
+// with other tools. Room password is redacted.
+func (o RoomOptions) JSON() string {
+	body, _ := json.Marshal(o.redact())
+	return string(body)
+}
+
+// String represents RoomOptions with one name / value pair per line.
+// Room password is redacted.
+func (o RoomOptions) String() string {
+	opts := o.redact().options()
+	lines := make([]string, len(opts))
+	for i, opt := range opts {
+		lines[i] = opt.Name + "\t" + opt.Value
+	}
+	return strings.Join(lines, "\n")
+}
+
+//==============================================================================
+
+// Rooms contains room JIDs, as returned by ejabberd muc_online_rooms
+// API.
+type Rooms []string
+
+// JSON represents Rooms as a JSON array, for further processing with
+// other tools.
+func (r Rooms) JSON() string {
+	body, _ := json.Marshal(r)
+	return string(body)
+}
+
+// String represents Rooms with one JID per line.
+func (r Rooms) String() string {
+	return strings.Join(r, "\n")
+}
+
+// Occupant is a user present in a room.
+type Occupant struct {
+	JID  string `json:"jid"`
+	Nick string `json:"nick"`
+	Role string `json:"role"` // moderator, participant or visitor
+}
+
+// Occupants is the list of users present in a room, as returned by
+// ejabberd get_room_occupants API.
+type Occupants []Occupant
+
+// JSON represents Occupants as a JSON array, for further processing
+// with other tools.
+func (o Occupants) JSON() string {
+	body, _ := json.Marshal(o)
+	return string(body)
+}
+
+// String represents Occupants with one occupant per line.
+func (o Occupants) String() string {
+	lines := make([]string, len(o))
+	for i, occupant := range o {
+		lines[i] = fmt.Sprintf("%s\t%s\t%s", occupant.JID, occupant.Nick, occupant.Role)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Affiliation is the affiliation of a user to a room.
+type Affiliation struct {
+	JID         string `json:"jid"`
+	Affiliation string `json:"affiliation"` // owner, admin, member or outcast
+	Reason      string `json:"reason,omitempty"`
+}
+
+// Affiliations is the list of users affiliated to a room, as returned
+// by ejabberd get_room_affiliations API.
+type Affiliations []Affiliation
+
+// JSON represents Affiliations as a JSON array, for further processing
+// with other tools.
+func (a Affiliations) JSON() string {
+	body, _ := json.Marshal(a)
+	return string(body)
+}
+
+// String represents Affiliations with one user per line.
+func (a Affiliations) String() string {
+	lines := make([]string, len(a))
+	for i, affiliation := range a {
+		lines[i] = fmt.Sprintf("%s\t%s\t%s", affiliation.JID, affiliation.Affiliation, affiliation.Reason)
+	}
+	return strings.Join(lines, "\n")
+}
+
+//==============================================================================
+
+// roomArgs are the arguments identifying a room in MUC commands.
+type roomArgs struct {
+	Name    string `json:"name"`
+	Service string `json:"service"`
+}
+
+// newRoomArgs splits a room JID, like room@conference.localhost, into
+// room name and MUC service.
+func newRoomArgs(roomJID string) (roomArgs, error) {
+	jid, err := parseJID(roomJID)
+	if err != nil || jid.username == "" || jid.domain == "" || jid.resource != "" {
+		return roomArgs{}, fmt.Errorf("invalid room jid: %s", roomJID)
+	}
+	return roomArgs{Name: jid.username, Service: jid.domain}, nil
+}
+
+// roomHost returns host, or the virtual host of a MUC service when
+// empty, assuming the service is a subdomain like conference.localhost.
+func roomHost(service, host string) string {
+	if host != "" {
+		return host
+	}
+	if i := strings.Index(service, "."); i >= 0 {
+		return service[i+1:]
+	}
+	return service
+}
+
+//==============================================================================
+
+type createRoomRequest struct {
+	JID     string       `json:"jid"`
+	Host    string       `json:"host"`
+	Options *RoomOptions `json:"options"`
+}
+
+func (c createRoomRequest) command() string {
+	if c.Options == nil {
+		return "create_room"
+	}
+	return "create_room_with_opts"
+}
+
+func (c createRoomRequest) params() (apiParams, error) {
+	room, err := newRoomArgs(c.JID)
+	if err != nil {
+		return apiParams{}, err
+	}
+
+	type createRoom struct {
+		roomArgs
+		Host    string       `json:"host"`
+		Options []roomOption `json:"options,omitempty"`
+	}
+
+	args := createRoom{roomArgs: room, Host: roomHost(room.Service, c.Host)}
+	if c.Options != nil {
+		args.Options = c.Options.options()
+	}
+
+	p, err := commandParams(c.command(), 1, args)
+	p.admin = true
+	return p, err
+}
+
+func (c createRoomRequest) parseResponse(body []byte) (Response, error) {
+	return parseAction(c.command(), body)
+}
+
+// CreateRoom creates a room with the default options of the MUC
+// service. host is the virtual host serving the room; when empty, it
+// is derived from the service, for example localhost for
+// room@conference.localhost. It requires admin rights.
+func (c Client) CreateRoom(roomJID, host string) error {
+	return c.CreateRoomContext(context.Background(), roomJID, host)
+}
+
+// CreateRoomContext is like CreateRoom but carries ctx into the API
+// call.
+func (c Client) CreateRoomContext(ctx context.Context, roomJID, host string) error {
+	_, err := c.call(ctx, createRoomRequest{JID: roomJID, Host: host})
+	return err
+}
+
+// CreateRoomWithOptions creates a room like CreateRoom, setting the
+// options that are not nil. It requires admin rights.
+func (c Client) CreateRoomWithOptions(roomJID, host string, options RoomOptions) error {
+	return c.CreateRoomWithOptionsContext(context.Background(), roomJID, host, options)
+}
+
+// CreateRoomWithOptionsContext is like CreateRoomWithOptions but
+// carries ctx into the API call.
+func (c Client) CreateRoomWithOptionsContext(ctx context.Context, roomJID, host string, options RoomOptions) error {
+	_, err := c.call(ctx, createRoomRequest{JID: roomJID, Host: host, Options: &options})
+	return err
+}
+
+//==============================================================================
+
+type destroyRoomRequest struct {
+	JID string `json:"jid"`
+}
+
+func (d destroyRoomRequest) params() (apiParams, error) {
+	room, err := newRoomArgs(d.JID)
+	if err != nil {
+		return apiParams{}, err
+	}
+
+	p, err := commandParams("destroy_room", 1, room)
+	p.admin = true
+	return p, err
+}
+
+func (d destroyRoomRequest) parseResponse(body []byte) (Response, error) {
+	return parseAction("destroy_room", body)
+}
+
+// DestroyRoom destroys a room, kicking out its occupants. It requires
+// admin rights.
+func (c Client) DestroyRoom(roomJID string) error {
+	return c.DestroyRoomContext(context.Background(), roomJID)
+}
+
+// DestroyRoomContext is like DestroyRoom but carries ctx into the API
+// call.
+func (c Client) DestroyRoomContext(ctx context.Context, roomJID string) error {
+	_, err := c.call(ctx, destroyRoomRequest{JID: roomJID})
+	return err
+}
+
+//==============================================================================
+
+type onlineRoomsRequest struct {
+	Service string `json:"service"`
+}
+
+func (o onlineRoomsRequest) params() (apiParams, error) {
+	if o.Service == "" {
+		o.Service = "global"
+	}
+
+	p, err := commandParams("muc_online_rooms", 1, o)
+	p.admin = true
+	p.idempotent = true
+	return p, err
+}
+
+func (o onlineRoomsRequest) parseResponse(body []byte) (Response, error) {
+	var resp Rooms
+	err := decodeResponse("muc_online_rooms", body, &resp)
+	return resp, err
+}
+
+// OnlineRooms returns the JIDs of the rooms existing on a MUC service,
+// like conference.localhost, or on all services when service is
+// empty. It requires admin rights.
+func (c Client) OnlineRooms(service string) (Rooms, error) {
+	return c.OnlineRoomsContext(context.Background(), service)
+}
+
+// OnlineRoomsContext is like OnlineRooms but carries ctx into the API
+// call.
+func (c Client) OnlineRoomsContext(ctx context.Context, service string) (Rooms, error) {
+	result, err := c.call(ctx, onlineRoomsRequest{Service: service})
+	if err != nil {
+		return Rooms{}, err
+	}
+	resp := result.(Rooms)
+	return resp, nil
+}
+
+//==============================================================================
+
+type getRoomOptionsRequest struct {
+	JID string `json:"jid"`
+}
+
+func (g getRoomOptionsRequest) params() (apiParams, error) {
+	room, err := newRoomArgs(g.JID)
+	if err != nil {
+		return apiParams{}, err
+	}
+
+	p, err := commandParams("get_room_options", 1, room)
+	p.admin = true
+	p.idempotent = true
+	return p, err
+}
+
+func (g getRoomOptionsRequest) parseResponse(body []byte) (Response, error) {
+	var data []roomOption
+	if err := decodeResponse("get_room_options", body, &data); err != nil {
+		return RoomOptions{}, err
+	}
+
+	var resp RoomOptions
+	for _, opt := range data {
+		if err := resp.Set(opt.Name, opt.Value); err != nil {
+			return RoomOptions{}, DecodeError{Command: "get_room_options", Body: body, Err: err}
+		}
+	}
+	return resp, nil
+}
+
+// GetRoomOptions returns the configuration of a room. It requires
+// admin rights.
+func (c Client) GetRoomOptions(roomJID string) (RoomOptions, error) {
+	return c.GetRoomOptionsContext(context.Background(), roomJID)
+}
+
+// GetRoomOptionsContext is like GetRoomOptions but carries ctx into
+// the API call.
+func (c Client) GetRoomOptionsContext(ctx context.Context, roomJID string) (RoomOptions, error) {
+	result, err := c.call(ctx, getRoomOptionsRequest{JID: roomJID})
+	if err != nil {
+		return RoomOptions{}, err
+	}
+	resp := result.(RoomOptions)
+	return resp, nil
+}
+
+//==============================================================================
+
+type changeRoomOptionRequest struct {
+	JID    string `json:"jid"`
+	Option string `json:"option"`
+	Value  string `json:"value"`
+}
+
+func (r changeRoomOptionRequest) params() (apiParams, error) {
+	room, err := newRoomArgs(r.JID)
+	if err != nil {
+		return apiParams{}, err
+	}
+	if r.Option == "" {
+		return apiParams{}, fmt.Errorf("required argument 'option' not provided")
+	}
+
+	type changeRoomOption struct {
+		roomArgs
+		Option string `json:"option"`
+		Value  string `json:"value"`
+	}
+
+	p, err := commandParams("change_room_option", 1, changeRoomOption{roomArgs: room, Option: r.Option, Value: r.Value})
+	p.admin = true
+	return p, err
+}
+
+func (r changeRoomOptionRequest) parseResponse(body []byte) (Response, error) {
+	return parseAction("change_room_option", body)
+}
+
+// ChangeRoomOption changes an option of a room, given its ejabberd
+// name and string value, like members_only and true. It requires
+// admin rights.
+func (c Client) ChangeRoomOption(roomJID, option, value string) error {
+	return c.ChangeRoomOptionContext(context.Background(), roomJID, option, value)
+}
+
+// ChangeRoomOptionContext is like ChangeRoomOption but carries ctx
+// into the API call.
+func (c Client) ChangeRoomOptionContext(ctx context.Context, roomJID, option, value string) error {
+	_, err := c.call(ctx, changeRoomOptionRequest{JID: roomJID, Option: option, Value: value})
+	return err
+}
+
+//==============================================================================
+
+type getRoomOccupantsRequest struct {
+	JID string `json:"jid"`
+}
+
+func (g getRoomOccupantsRequest) params() (apiParams, error) {
+	room, err := newRoomArgs(g.JID)
+	if err != nil {
+		return apiParams{}, err
+	}
+
+	p, err := commandParams("get_room_occupants", 1, room)
+	p.admin = true
+	p.idempotent = true
+	return p, err
+}
+
+func (g getRoomOccupantsRequest) parseResponse(body []byte) (Response, error) {
+	var resp Occupants
+	err := decodeResponse("get_room_occupants", body, &resp)
+	return resp, err
+}
+
+// GetRoomOccupants returns the users present in a room. It requires
+// admin rights.
+func (c Client) GetRoomOccupants(roomJID string) (Occupants, error) {
+	return c.GetRoomOccupantsContext(context.Background(), roomJID)
+}
+
+// GetRoomOccupantsContext is like GetRoomOccupants but carries ctx
+// into the API call.
+func (c Client) GetRoomOccupantsContext(ctx context.Context, roomJID string) (Occupants, error) {
+	result, err := c.call(ctx, getRoomOccupantsRequest{JID: roomJID})
+	if err != nil {
+		return Occupants{}, err
+	}
+	resp := result.(Occupants)
+	return resp, nil
+}
+
+//==============================================================================
+
+type getRoomAffiliationsRequest struct {
+	JID string `json:"jid"`
+}
+
+func (g getRoomAffiliationsRequest) params() (apiParams, error) {
+	room, err := newRoomArgs(g.JID)
+	if err != nil {
+		return apiParams{}, err
+	}
+
+	p, err := commandParams("get_room_affiliations", 1, room)
+	p.admin = true
+	p.idempotent = true
+	return p, err
+}
+
+func (g getRoomAffiliationsRequest) parseResponse(body []byte) (Response, error) {
+	var data []struct {
+		Username    string `json:"username"`
+		Domain      string `json:"domain"`
+		Affiliation string `json:"affiliation"`
+		Reason      string `json:"reason"`
+	}
+	if err := decodeResponse("get_room_affiliations", body, &data); err != nil {
+		return Affiliations{}, err
+	}
+
+	resp := make(Affiliations, len(data))
+	for i, a := range data {
+		resp[i] = Affiliation{
+			JID:         jid{username: a.Username, domain: a.Domain}.bare(),
+			Affiliation: a.Affiliation,
+			Reason:      a.Reason,
+		}
+	}
+	return resp, nil
+}
+
+// GetRoomAffiliations returns the users affiliated to a room. It
+// requires admin rights.
+func (c Client) GetRoomAffiliations(roomJID string) (Affiliations, error) {
+	return c.GetRoomAffiliationsContext(context.Background(), roomJID)
+}
+
+// GetRoomAffiliationsContext is like GetRoomAffiliations but carries
+// ctx into the API call.
+func (c Client) GetRoomAffiliationsContext(ctx context.Context, roomJID string) (Affiliations, error) {
+	result, err := c.call(ctx, getRoomAffiliationsRequest{JID: roomJID})
+	if err != nil {
+		return Affiliations{}, err
+	}
+	resp := result.(Affiliations)
+	return resp, nil
+}
+
+//==============================================================================
+
+type setRoomAffiliationRequest struct {
+	JID         string `json:"jid"`
+	User        string `json:"user"`
+	Affiliation string `json:"affiliation"`
+}
+
+func (s setRoomAffiliationRequest) params() (apiParams, error) {
+	room, err := newRoomArgs(s.JID)
+	if err != nil {
+		return apiParams{}, err
+	}
+	if _, err := parseJID(s.User); err != nil {
+		return apiParams{}, err
+	}
+	switch s.Affiliation {
+	case "owner", "admin", "member", "outcast", "none":
+	default:
+		return apiParams{}, fmt.Errorf("unknown affiliation: %s", s.Affiliation)
+	}
+
+	type setRoomAffiliation struct {
+		roomArgs
+		JID         string `json:"jid"`
+		Affiliation string `json:"affiliation"`
+	}
+
+	p, err := commandParams("set_room_affiliation", 1, setRoomAffiliation{roomArgs: room, JID: s.User, Affiliation: s.Affiliation})
+	p.admin = true
+	return p, err
+}
+
+func (s setRoomAffiliationRequest) parseResponse(body []byte) (Response, error) {
+	return parseAction("set_room_affiliation", body)
+}
+
+// SetRoomAffiliation changes the affiliation of a user to a room:
+// owner, admin, member, outcast or none to remove it. It requires
+// admin rights.
+func (c Client) SetRoomAffiliation(roomJID, bareJID, affiliation string) error {
+	return c.SetRoomAffiliationContext(context.Background(), roomJID, bareJID, affiliation)
+}
+
+// SetRoomAffiliationContext is like SetRoomAffiliation but carries ctx
+// into the API call.
+func (c Client) SetRoomAffiliationContext(ctx context.Context, roomJID, bareJID, affiliation string) error {
+	_, err := c.call(ctx, setRoomAffiliationRequest{JID: roomJID, User: bareJID, Affiliation: affiliation})
+	return err
+}
+
+//==============================================================================
+
+type sendDirectInvitationRequest struct {
+	JID      string   `json:"jid"`
+	Password string   `json:"password"`
+	Reason   string   `json:"reason"`
+	Users    []string `json:"users"`
+}
+
+func (s sendDirectInvitationRequest) params() (apiParams, error) {
+	room, err := newRoomArgs(s.JID)
+	if err != nil {
+		return apiParams{}, err
+	}
+	if len(s.Users) == 0 {
+		return apiParams{}, fmt.Errorf("required argument 'users' not provided")
+	}
+	for _, user := range s.Users {
+		if _, err := parseJID(user); err != nil {
+			return apiParams{}, fmt.Errorf("invalid jid: %s", user)
+		}
+	}
+
+	type sendDirectInvitation struct {
+		roomArgs
+		Password string   `json:"password"`
+		Reason   string   `json:"reason"`
+		Users    []string `json:"users"`
+	}
+
+	p, err := commandParams("send_direct_invitation", 1, sendDirectInvitation{
+		roomArgs: room,
+		Password: s.Password,
+		Reason:   s.Reason,
+		Users:    s.Users,
+	})
+	p.admin = true
+	return p, err
+}
+
+func (s sendDirectInvitationRequest) parseResponse(body []byte) (Response, error) {
+	return parseAction("send_direct_invitation", body)
+}
+
+// SendDirectInvitation invites users to a room (XEP-0249). password
+// is only needed for password protected rooms. It requires admin
+// rights.
+func (c Client) SendDirectInvitation(roomJID, password, reason string, users []string) error {
+	return c.SendDirectInvitationContext(context.Background(), roomJID, password, reason, users)
+}
+
+// SendDirectInvitationContext is like SendDirectInvitation but carries
+// ctx into the API call.
+func (c Client) SendDirectInvitationContext(ctx context.Context, roomJID, password, reason string, users []string) error {
+	_, err := c.call(ctx, sendDirectInvitationRequest{JID: roomJID, Password: password, Reason: reason, Users: users})
+	return err
+}
@@ -0,0 +1,101 @@
+package ejabberd_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/processone/ejabberd-api"
+)
+
+func Test_CreateRoomWithOptions(t *testing.T) {
+	server, calls := newAPIServer(t, func(call apiCall) (int, string) {
+		return 200, `0`
+	})
+	defer server.Close()
+
+	client := ejabberd.Client{BaseURL: server.URL}
+
+	if err := client.CreateRoom("team@conference.localhost", ""); err != nil {
+		t.Fatalf("CreateRoom failed: %s", err)
+	}
+	options := ejabberd.RoomOptions{
+		Title:       ejabberd.RoomString("Team"),
+		Public:      ejabberd.RoomBool(false),
+		MaxUsers:    ejabberd.RoomInt(50),
+		MembersOnly: ejabberd.RoomBool(true),
+	}
+	if err := client.CreateRoomWithOptions("ops@conference.localhost", "example.com", options); err != nil {
+		t.Fatalf("CreateRoomWithOptions failed: %s", err)
+	}
+	if err := client.CreateRoom("conference.localhost", ""); err == nil {
+		t.Errorf("CreateRoom accepted a JID without room name")
+	}
+
+	checkCalls(t, *calls, []apiCall{
+		{command: "create_room", admin: true, args: map[string]interface{}{
+			"name": "team", "service": "conference.localhost", "host": "localhost",
+		}},
+		{command: "create_room_with_opts", admin: true, args: map[string]interface{}{
+			"name": "ops", "service": "conference.localhost", "host": "example.com",
+			"options": []interface{}{
+				map[string]interface{}{"name": "max_users", "value": "50"},
+				map[string]interface{}{"name": "members_only", "value": "true"},
+				map[string]interface{}{"name": "public", "value": "false"},
+				map[string]interface{}{"name": "title", "value": "Team"},
+			},
+		}},
+	})
+}
+
+func Test_GetRoomOptions(t *testing.T) {
+	server, _ := newAPIServer(t, func(call apiCall) (int, string) {
+		return 200, `[{"name":"title","value":"Team"},
+		              {"name":"persistent","value":"true"},
+		              {"name":"max_users","value":"200"},
+		              {"name":"password","value":"s3cret"},
+		              {"name":"presence_broadcast","value":"[moderator,participant,visitor]"}]`
+	})
+	defer server.Close()
+
+	client := ejabberd.Client{BaseURL: server.URL}
+
+	options, err := client.GetRoomOptions("team@conference.localhost")
+	if err != nil {
+		t.Fatalf("GetRoomOptions failed: %s", err)
+	}
+	if options.Title == nil || *options.Title != "Team" ||
+		options.Persistent == nil || !*options.Persistent ||
+		options.MaxUsers == nil || *options.MaxUsers != 200 ||
+		options.Public != nil ||
+		options.Other["presence_broadcast"] != "[moderator,participant,visitor]" {
+		t.Errorf("Incorrect room options %s", options.JSON())
+	}
+	if options.Password == nil || *options.Password != "s3cret" {
+		t.Errorf("Incorrect room password %v", options.Password)
+	}
+	if strings.Contains(options.JSON(), "s3cret") || strings.Contains(options.String(), "s3cret") {
+		t.Errorf("Room password not redacted in %s", options)
+	}
+}
+
+func Test_GetRoomAffiliations(t *testing.T) {
+	server, _ := newAPIServer(t, func(call apiCall) (int, string) {
+		return 200, `[{"username":"alice","domain":"localhost","affiliation":"owner","reason":""},
+		              {"username":"spammer","domain":"example.com","affiliation":"outcast","reason":"Spam"}]`
+	})
+	defer server.Close()
+
+	client := ejabberd.Client{BaseURL: server.URL}
+
+	affiliations, err := client.GetRoomAffiliations("team@conference.localhost")
+	if err != nil {
+		t.Fatalf("GetRoomAffiliations failed: %s", err)
+	}
+	want := ejabberd.Affiliations{
+		{JID: "alice@localhost", Affiliation: "owner"},
+		{JID: "spammer@example.com", Affiliation: "outcast", Reason: "Spam"},
+	}
+	if affiliations.JSON() != want.JSON() {
+		t.Errorf("GetRoomAffiliations = %s; want %s", affiliations.JSON(), want.JSON())
+	}
+}
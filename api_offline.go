@@ -0,0 +1,190 @@
+package ejabberd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Offline message store commands, from mod_offline and
+// mod_admin_extra. Message retrieval and per-user deletion are only
+// available on ejabberd servers exposing get_offline_messages and
+// delete_offline_messages commands.
+
+//==============================================================================
+
+// OfflineMessage is a message waiting in the offline store of a user.
+type OfflineMessage struct {
+	From      string    `json:"from"`
+	To        string    `json:"to"`
+	Timestamp time.Time `json:"timestamp"`
+	Packet    string    `json:"packet"` // XML stanza
+}
+
+func (o OfflineMessage) String() string {
+	return fmt.Sprintf("%s\t%s\t%s", o.Timestamp.Format(time.RFC3339), o.From, o.Packet)
+}
+
+// OfflineMessages is the content of the offline store of a user, as
+// returned by ejabberd get_offline_messages API.
+type OfflineMessages []OfflineMessage
+
+// JSON represents OfflineMessages as a JSON array, for further
+// processing with other tools.
+func (o OfflineMessages) JSON() string {
+	body, _ := json.Marshal(o)
+	return string(body)
+}
+
+// String represents OfflineMessages with one message per line.
+func (o OfflineMessages) String() string {
+	lines := make([]string, len(o))
+	for i, msg := range o {
+		lines[i] = msg.String()
+	}
+	return strings.Join(lines, "\n")
+}
+
+//==============================================================================
+
+type getOfflineMessagesRequest struct {
+	JID string `json:"jid"`
+}
+
+func (g getOfflineMessagesRequest) params() (apiParams, error) {
+	jid, err := parseJID(g.JID)
+	if err != nil {
+		return apiParams{}, err
+	}
+
+	type getOfflineMessages struct {
+		User   string `json:"user"`
+		Server string `json:"server"`
+	}
+
+	p, err := commandParams("get_offline_messages", 1, getOfflineMessages{
+		User:   jid.username,
+		Server: jid.domain,
+	})
+	p.idempotent = true
+	return p, err
+}
+
+func (g getOfflineMessagesRequest) parseResponse(body []byte) (Response, error) {
+	var data []struct {
+		From      string `json:"from"`
+		To        string `json:"to"`
+		Timestamp string `json:"timestamp"`
+		Packet    string `json:"packet"`
+	}
+	if err := decodeResponse("get_offline_messages", body, &data); err != nil {
+		return OfflineMessages{}, err
+	}
+
+	resp := make(OfflineMessages, len(data))
+	for i, msg := range data {
+		resp[i] = OfflineMessage{
+			From:      msg.From,
+			To:        msg.To,
+			Timestamp: parseTimestamp(msg.Timestamp),
+			Packet:    msg.Packet,
+		}
+	}
+	return resp, nil
+}
+
+// GetOfflineMessages returns the messages waiting in the offline store
+// of a user, without removing them. It can be called as a user, for
+// your own offline store, or as an admin for any user.
+func (c Client) GetOfflineMessages(bareJID string) (OfflineMessages, error) {
+	return c.GetOfflineMessagesContext(context.Background(), bareJID)
+}
+
+// GetOfflineMessagesContext is like GetOfflineMessages but carries ctx
+// into the API call.
+func (c Client) GetOfflineMessagesContext(ctx context.Context, bareJID string) (OfflineMessages, error) {
+	result, err := c.call(ctx, getOfflineMessagesRequest{JID: bareJID})
+	if err != nil {
+		return OfflineMessages{}, err
+	}
+	resp := result.(OfflineMessages)
+	return resp, nil
+}
+
+//==============================================================================
+
+type deleteOfflineMessagesRequest struct {
+	JID string `json:"jid"`
+}
+
+func (d deleteOfflineMessagesRequest) params() (apiParams, error) {
+	jid, err := parseJID(d.JID)
+	if err != nil {
+		return apiParams{}, err
+	}
+
+	type deleteOfflineMessages struct {
+		User   string `json:"user"`
+		Server string `json:"server"`
+	}
+
+	p, err := commandParams("delete_offline_messages", 1, deleteOfflineMessages{
+		User:   jid.username,
+		Server: jid.domain,
+	})
+	p.admin = true
+	return p, err
+}
+
+func (d deleteOfflineMessagesRequest) parseResponse(body []byte) (Response, error) {
+	return parseAction("delete_offline_messages", body)
+}
+
+// DeleteOfflineMessages empties the offline store of a user. It
+// requires admin rights.
+func (c Client) DeleteOfflineMessages(bareJID string) error {
+	return c.DeleteOfflineMessagesContext(context.Background(), bareJID)
+}
+
+// DeleteOfflineMessagesContext is like DeleteOfflineMessages but
+// carries ctx into the API call.
+func (c Client) DeleteOfflineMessagesContext(ctx context.Context, bareJID string) error {
+	_, err := c.call(ctx, deleteOfflineMessagesRequest{JID: bareJID})
+	return err
+}
+
+//==============================================================================
+
+type deleteOldMessagesRequest struct {
+	Days int `json:"days"`
+}
+
+func (d deleteOldMessagesRequest) params() (apiParams, error) {
+	if d.Days <= 0 {
+		return apiParams{}, fmt.Errorf("days must be positive: %d", d.Days)
+	}
+
+	p, err := commandParams("delete_old_messages", 1, d)
+	p.admin = true
+	return p, err
+}
+
+func (d deleteOldMessagesRequest) parseResponse(body []byte) (Response, error) {
+	return parseAction("delete_old_messages", body)
+}
+
+// DeleteOldMessages removes from the offline store of all users the
+// messages older than the given number of days. It requires admin
+// rights.
+func (c Client) DeleteOldMessages(days int) error {
+	return c.DeleteOldMessagesContext(context.Background(), days)
+}
+
+// DeleteOldMessagesContext is like DeleteOldMessages but carries ctx
+// into the API call.
+func (c Client) DeleteOldMessagesContext(ctx context.Context, days int) error {
+	_, err := c.call(ctx, deleteOldMessagesRequest{Days: days})
+	return err
+}
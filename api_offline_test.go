@@ -0,0 +1,57 @@
+package ejabberd_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/processone/ejabberd-api"
+)
+
+func Test_GetOfflineMessages(t *testing.T) {
+	server, calls := newAPIServer(t, func(call apiCall) (int, string) {
+		return 200, `[{"from":"bob@localhost/phone","to":"alice@localhost",
+		               "timestamp":"2024-05-13T15:32:02Z",
+		               "packet":"<message><body>Hi</body></message>"}]`
+	})
+	defer server.Close()
+
+	client := ejabberd.Client{BaseURL: server.URL, Token: ejabberd.OAuthToken{JID: "alice@localhost"}}
+
+	messages, err := client.GetOfflineMessages("alice@localhost")
+	if err != nil {
+		t.Fatalf("GetOfflineMessages failed: %s", err)
+	}
+	want := ejabberd.OfflineMessages{{
+		From:      "bob@localhost/phone",
+		To:        "alice@localhost",
+		Timestamp: time.Date(2024, 5, 13, 15, 32, 2, 0, time.UTC),
+		Packet:    "<message><body>Hi</body></message>",
+	}}
+	if messages.JSON() != want.JSON() {
+		t.Errorf("GetOfflineMessages = %s; want %s", messages.JSON(), want.JSON())
+	}
+
+	checkCalls(t, *calls, []apiCall{
+		{command: "get_offline_messages", args: map[string]interface{}{"user": "alice", "server": "localhost"}},
+	})
+}
+
+func Test_DeleteOldMessages(t *testing.T) {
+	server, calls := newAPIServer(t, func(call apiCall) (int, string) {
+		return 200, `0`
+	})
+	defer server.Close()
+
+	client := ejabberd.Client{BaseURL: server.URL}
+
+	if err := client.DeleteOldMessages(0); err == nil {
+		t.Errorf("DeleteOldMessages accepted 0 days")
+	}
+	if err := client.DeleteOldMessages(30); err != nil {
+		t.Fatalf("DeleteOldMessages failed: %s", err)
+	}
+
+	checkCalls(t, *calls, []apiCall{
+		{command: "delete_old_messages", admin: true, args: map[string]interface{}{"days": float64(30)}},
+	})
+}
@@ -0,0 +1,300 @@
+package ejabberd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Presence and last activity commands, from mod_admin_extra.
+
+//==============================================================================
+
+// Presence describes the presence of a user session.
+type Presence struct {
+	JID      string `json:"jid"` // Full JID of the session
+	Resource string `json:"resource"`
+	Type     string `json:"type,omitempty"` // available or unavailable
+	Show     string `json:"show"`           // available, away, chat, dnd or xa
+	Status   string `json:"status"`
+
+	// Priority is used by SetPresence. It is not reported by
+	// GetPresence.
+	Priority int `json:"priority,omitempty"`
+}
+
+// JSON represents Presence as a JSON string, for further processing
+// with other tools.
+func (p Presence) JSON() string {
+	body, _ := json.Marshal(p)
+	return string(body)
+}
+
+func (p Presence) String() string {
+	return fmt.Sprintf("%s\t%s\t%s", p.JID, p.Show, p.Status)
+}
+
+//==============================================================================
+
+type getPresenceRequest struct {
+	JID string `json:"jid"`
+}
+
+func (g getPresenceRequest) params() (apiParams, error) {
+	jid, err := parseJID(g.JID)
+	if err != nil {
+		return apiParams{}, err
+	}
+
+	type getPresence struct {
+		User   string `json:"user"`
+		Server string `json:"server"`
+	}
+
+	p, err := commandParams("get_presence", 1, getPresence{
+		User:   jid.username,
+		Server: jid.domain,
+	})
+	p.idempotent = true
+	return p, err
+}
+
+func (g getPresenceRequest) parseResponse(body []byte) (Response, error) {
+	var resp Presence
+	if err := decodeResponse("get_presence", body, &resp); err != nil {
+		return Presence{}, err
+	}
+	if j, err := parseJID(resp.JID); err == nil {
+		resp.Resource = j.resource
+	}
+	return resp, nil
+}
+
+// GetPresence returns the presence of the session of a user with the
+// highest priority. Show is unavailable when the user is offline. It
+// can be called as a user, for your own presence, or as an admin for
+// any user.
+func (c Client) GetPresence(bareJID string) (Presence, error) {
+	return c.GetPresenceContext(context.Background(), bareJID)
+}
+
+// GetPresenceContext is like GetPresence but carries ctx into the API
+// call.
+func (c Client) GetPresenceContext(ctx context.Context, bareJID string) (Presence, error) {
+	result, err := c.call(ctx, getPresenceRequest{JID: bareJID})
+	if err != nil {
+		return Presence{}, err
+	}
+	resp := result.(Presence)
+	return resp, nil
+}
+
+//==============================================================================
+
+type setPresenceRequest struct {
+	Presence Presence `json:"presence"`
+}
+
+func (s setPresenceRequest) params() (apiParams, error) {
+	p := s.Presence
+	jid, err := parseJID(p.JID)
+	if err != nil {
+		return apiParams{}, err
+	}
+	if jid.resource == "" {
+		return apiParams{}, fmt.Errorf("full jid with resource required: %s", p.JID)
+	}
+
+	if p.Type == "" {
+		p.Type = "available"
+	}
+	if p.Show == "" {
+		p.Show = "available"
+	}
+	switch p.Show {
+	case "available", "away", "chat", "dnd", "xa":
+	default:
+		return apiParams{}, fmt.Errorf("unknown presence show: %s", p.Show)
+	}
+
+	type setPresence struct {
+		User     string `json:"user"`
+		Host     string `json:"host"`
+		Resource string `json:"resource"`
+		Type     string `json:"type"`
+		Show     string `json:"show"`
+		Status   string `json:"status"`
+		Priority int    `json:"priority"`
+	}
+
+	params, err := commandParams("set_presence", 1, setPresence{
+		User:     jid.username,
+		Host:     jid.domain,
+		Resource: jid.resource,
+		Type:     p.Type,
+		Show:     p.Show,
+		Status:   p.Status,
+		Priority: p.Priority,
+	})
+	params.admin = true
+	return params, err
+}
+
+func (s setPresenceRequest) parseResponse(body []byte) (Response, error) {
+	return parseAction("set_presence", body)
+}
+
+// SetPresence sets the presence of a connected session, identified by
+// the full JID presence.JID. Type and Show default to available. It
+// requires admin rights.
+func (c Client) SetPresence(presence Presence) error {
+	return c.SetPresenceContext(context.Background(), presence)
+}
+
+// SetPresenceContext is like SetPresence but carries ctx into the API
+// call.
+func (c Client) SetPresenceContext(ctx context.Context, presence Presence) error {
+	_, err := c.call(ctx, setPresenceRequest{Presence: presence})
+	return err
+}
+
+//==============================================================================
+
+// LastActivity contains the result of the call to ejabberd get_last
+// API.
+type LastActivity struct {
+	JID       string    `json:"jid"`
+	Online    bool      `json:"online"`
+	Timestamp time.Time `json:"timestamp"` // Zero when never connected.
+	Status    string    `json:"status"`    // Last status text
+}
+
+// JSON represents LastActivity as a JSON string, for further
+// processing with other tools.
+func (l LastActivity) JSON() string {
+	body, _ := json.Marshal(l)
+	return string(body)
+}
+
+func (l LastActivity) String() string {
+	if l.Online {
+		return fmt.Sprintf("%s is online", l.JID)
+	}
+	if l.Timestamp.IsZero() {
+		return fmt.Sprintf("%s never connected", l.JID)
+	}
+	return fmt.Sprintf("%s last seen on %s: %s", l.JID, l.Timestamp.Format(time.RFC3339), l.Status)
+}
+
+type getLastRequest struct {
+	JID string `json:"jid"`
+}
+
+func (g getLastRequest) params() (apiParams, error) {
+	jid, err := parseJID(g.JID)
+	if err != nil {
+		return apiParams{}, err
+	}
+
+	type getLast struct {
+		User string `json:"user"`
+		Host string `json:"host"`
+	}
+
+	p, err := commandParams("get_last", 1, getLast{
+		User: jid.username,
+		Host: jid.domain,
+	})
+	p.idempotent = true
+	return p, err
+}
+
+func (g getLastRequest) parseResponse(body []byte) (Response, error) {
+	var data struct {
+		Timestamp string `json:"timestamp"`
+		Status    string `json:"status"`
+	}
+	if err := decodeResponse("get_last", body, &data); err != nil {
+		return LastActivity{}, err
+	}
+
+	resp := LastActivity{JID: g.JID, Timestamp: parseTimestamp(data.Timestamp)}
+	switch data.Status {
+	case "ONLINE":
+		resp.Online = true
+	case "NOT FOUND":
+		resp.Timestamp = time.Time{}
+	default:
+		resp.Status = data.Status
+	}
+	return resp, nil
+}
+
+// GetLast returns the last activity of a user: whether it is online,
+// and if not, the time and status of its last disconnection. It can
+// be called as a user for your own account, or as an admin for any
+// user.
+func (c Client) GetLast(bareJID string) (LastActivity, error) {
+	return c.GetLastContext(context.Background(), bareJID)
+}
+
+// GetLastContext is like GetLast but carries ctx into the API call.
+func (c Client) GetLastContext(ctx context.Context, bareJID string) (LastActivity, error) {
+	result, err := c.call(ctx, getLastRequest{JID: bareJID})
+	if err != nil {
+		return LastActivity{}, err
+	}
+	resp := result.(LastActivity)
+	return resp, nil
+}
+
+//==============================================================================
+
+type setLastRequest struct {
+	JID       string    `json:"jid"`
+	Timestamp time.Time `json:"timestamp"`
+	Status    string    `json:"status"`
+}
+
+func (s setLastRequest) params() (apiParams, error) {
+	jid, err := parseJID(s.JID)
+	if err != nil {
+		return apiParams{}, err
+	}
+	if s.Timestamp.IsZero() {
+		return apiParams{}, fmt.Errorf("required argument 'timestamp' not provided")
+	}
+
+	type setLast struct {
+		User      string `json:"user"`
+		Host      string `json:"host"`
+		Timestamp int64  `json:"timestamp"`
+		Status    string `json:"status"`
+	}
+
+	p, err := commandParams("set_last", 1, setLast{
+		User:      jid.username,
+		Host:      jid.domain,
+		Timestamp: s.Timestamp.Unix(),
+		Status:    s.Status,
+	})
+	p.admin = true
+	return p, err
+}
+
+func (s setLastRequest) parseResponse(body []byte) (Response, error) {
+	return parseAction("set_last", body)
+}
+
+// SetLast sets the last activity time and status of a user. It
+// requires admin rights.
+func (c Client) SetLast(bareJID string, timestamp time.Time, status string) error {
+	return c.SetLastContext(context.Background(), bareJID, timestamp, status)
+}
+
+// SetLastContext is like SetLast but carries ctx into the API call.
+func (c Client) SetLastContext(ctx context.Context, bareJID string, timestamp time.Time, status string) error {
+	_, err := c.call(ctx, setLastRequest{JID: bareJID, Timestamp: timestamp, Status: status})
+	return err
+}
@@ -0,0 +1,82 @@
+package ejabberd_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/processone/ejabberd-api"
+)
+
+func Test_GetPresence(t *testing.T) {
+	server, _ := newAPIServer(t, func(call apiCall) (int, string) {
+		return 200, `{"jid":"alice@localhost/phone","show":"dnd","status":"In a meeting"}`
+	})
+	defer server.Close()
+
+	client := ejabberd.Client{BaseURL: server.URL, Token: ejabberd.OAuthToken{JID: "alice@localhost"}}
+
+	presence, err := client.GetPresence("alice@localhost")
+	if err != nil {
+		t.Fatalf("GetPresence failed: %s", err)
+	}
+	want := ejabberd.Presence{JID: "alice@localhost/phone", Resource: "phone", Show: "dnd", Status: "In a meeting"}
+	if presence != want {
+		t.Errorf("GetPresence = %+v; want %+v", presence, want)
+	}
+}
+
+func Test_GetLast(t *testing.T) {
+	server, _ := newAPIServer(t, func(call apiCall) (int, string) {
+		switch call.args["user"] {
+		case "alice":
+			return 200, `{"timestamp":"2024-05-13T15:32:02.060109Z","status":"ONLINE"}`
+		case "bob":
+			return 200, `{"timestamp":"2024-05-12T08:00:00Z","status":"Gone fishing"}`
+		}
+		return 200, `{"timestamp":"1970-01-01T00:00:00Z","status":"NOT FOUND"}`
+	})
+	defer server.Close()
+
+	client := ejabberd.Client{BaseURL: server.URL}
+
+	var tests = []struct {
+		jid    string
+		online bool
+		status string
+		time   time.Time
+	}{
+		{"alice@localhost", true, "", time.Date(2024, 5, 13, 15, 32, 2, 60109000, time.UTC)},
+		{"bob@localhost", false, "Gone fishing", time.Date(2024, 5, 12, 8, 0, 0, 0, time.UTC)},
+		{"carol@localhost", false, "", time.Time{}},
+	}
+	for _, test := range tests {
+		last, err := client.GetLast(test.jid)
+		if err != nil {
+			t.Errorf("GetLast(%s) failed: %s", test.jid, err)
+			continue
+		}
+		if last.Online != test.online || last.Status != test.status || !last.Timestamp.Equal(test.time) {
+			t.Errorf("GetLast(%s) = %+v", test.jid, last)
+		}
+	}
+}
+
+func Test_SetLast(t *testing.T) {
+	server, calls := newAPIServer(t, func(call apiCall) (int, string) {
+		return 200, `0`
+	})
+	defer server.Close()
+
+	client := ejabberd.Client{BaseURL: server.URL}
+
+	timestamp := time.Date(2024, 5, 13, 15, 32, 2, 0, time.UTC)
+	if err := client.SetLast("alice@localhost", timestamp, "Migrated"); err != nil {
+		t.Fatalf("SetLast failed: %s", err)
+	}
+
+	checkCalls(t, *calls, []apiCall{
+		{command: "set_last", admin: true, args: map[string]interface{}{
+			"user": "alice", "host": "localhost", "timestamp": float64(timestamp.Unix()), "status": "Migrated",
+		}},
+	})
+}
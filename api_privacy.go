@@ -0,0 +1,324 @@
+package ejabberd
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Privacy lists and blocking commands, from mod_privacy and
+// mod_blocking. Block and unblock requests (XEP-0191) are sent as IQ
+// stanzas on behalf of the user, through send_stanza.
+
+//==============================================================================
+
+// PrivacyItem is a rule of a privacy list (XEP-0016).
+type PrivacyItem struct {
+	Type   string `json:"type,omitempty"` // jid, group or subscription. Empty matches everything.
+	Value  string `json:"value,omitempty"`
+	Action string `json:"action"` // allow or deny
+	Order  int    `json:"order"`
+
+	// Stanzas restricts the rule to some stanzas: message, iq,
+	// presence-in or presence-out. Empty matches all stanzas.
+	Stanzas []string `json:"stanzas,omitempty"`
+}
+
+// PrivacyList is a named list of privacy rules.
+type PrivacyList struct {
+	Name  string        `json:"name"`
+	Items []PrivacyItem `json:"items"`
+}
+
+// query returns the jabber:iq:privacy query setting the list.
+func (p PrivacyList) query() (string, error) {
+	if p.Name == "" {
+		return "", fmt.Errorf("required privacy list name not provided")
+	}
+
+	type stanza struct {
+		XMLName xml.Name
+	}
+	type item struct {
+		Type    string   `xml:"type,attr,omitempty"`
+		Value   string   `xml:"value,attr,omitempty"`
+		Action  string   `xml:"action,attr"`
+		Order   int      `xml:"order,attr"`
+		Stanzas []stanza `xml:",omitempty"`
+	}
+	type list struct {
+		Name  string `xml:"name,attr"`
+		Items []item `xml:"item"`
+	}
+	type query struct {
+		XMLName xml.Name `xml:"jabber:iq:privacy query"`
+		List    list     `xml:"list"`
+	}
+
+	q := query{List: list{Name: p.Name}}
+	for _, i := range p.Items {
+		switch i.Type {
+		case "", "jid", "group", "subscription":
+		default:
+			return "", fmt.Errorf("unknown privacy item type: %s", i.Type)
+		}
+		switch i.Action {
+		case "allow", "deny":
+		default:
+			return "", fmt.Errorf("unknown privacy item action: %s", i.Action)
+		}
+
+		it := item{Type: i.Type, Value: i.Value, Action: i.Action, Order: i.Order}
+		for _, s := range i.Stanzas {
+			switch s {
+			case "message", "iq", "presence-in", "presence-out":
+			default:
+				return "", fmt.Errorf("unknown privacy item stanza: %s", s)
+			}
+			it.Stanzas = append(it.Stanzas, stanza{XMLName: xml.Name{Local: s}})
+		}
+		q.List.Items = append(q.List.Items, it)
+	}
+
+	body, err := xml.Marshal(q)
+	return string(body), err
+}
+
+type privacySetRequest struct {
+	JID  string      `json:"jid"`
+	List PrivacyList `json:"list"`
+}
+
+func (p privacySetRequest) params() (apiParams, error) {
+	jid, err := parseJID(p.JID)
+	if err != nil {
+		return apiParams{}, err
+	}
+	query, err := p.List.query()
+	if err != nil {
+		return apiParams{}, err
+	}
+
+	type privacySet struct {
+		User     string `json:"user"`
+		Host     string `json:"host"`
+		XMLQuery string `json:"xmlquery"`
+	}
+
+	params, err := commandParams("privacy_set", 1, privacySet{
+		User:     jid.username,
+		Host:     jid.domain,
+		XMLQuery: query,
+	})
+	params.admin = true
+	return params, err
+}
+
+func (p privacySetRequest) parseResponse(body []byte) (Response, error) {
+	return parseAction("privacy_set", body)
+}
+
+// SetPrivacyList creates or replaces a privacy list of a user. It
+// requires admin rights.
+func (c Client) SetPrivacyList(bareJID string, list PrivacyList) error {
+	return c.SetPrivacyListContext(context.Background(), bareJID, list)
+}
+
+// SetPrivacyListContext is like SetPrivacyList but carries ctx into
+// the API call.
+func (c Client) SetPrivacyListContext(ctx context.Context, bareJID string, list PrivacyList) error {
+	_, err := c.call(ctx, privacySetRequest{JID: bareJID, List: list})
+	return err
+}
+
+//==============================================================================
+
+// BlockItem is an entry of a user block list (XEP-0191).
+type BlockItem struct {
+	JID string `json:"jid"`
+}
+
+// BlockList contains the entries of a user block list, as returned by
+// ejabberd get_blocklist API.
+type BlockList []BlockItem
+
+// JSON represents BlockList as a JSON array, for further processing
+// with other tools.
+func (b BlockList) JSON() string {
+	body, _ := json.Marshal(b)
+	return string(body)
+}
+
+// String represents BlockList with one JID per line.
+func (b BlockList) String() string {
+	lines := make([]string, len(b))
+	for i, item := range b {
+		lines[i] = item.JID
+	}
+	return strings.Join(lines, "\n")
+}
+
+type getBlockListRequest struct {
+	JID string `json:"jid"`
+}
+
+func (g getBlockListRequest) params() (apiParams, error) {
+	jid, err := parseJID(g.JID)
+	if err != nil {
+		return apiParams{}, err
+	}
+
+	type getBlockList struct {
+		User string `json:"user"`
+		Host string `json:"host"`
+	}
+
+	p, err := commandParams("get_blocklist", 1, getBlockList{
+		User: jid.username,
+		Host: jid.domain,
+	})
+	p.idempotent = true
+	return p, err
+}
+
+func (g getBlockListRequest) parseResponse(body []byte) (Response, error) {
+	var jids []string
+	if err := decodeResponse("get_blocklist", body, &jids); err != nil {
+		return BlockList{}, err
+	}
+
+	resp := make(BlockList, len(jids))
+	for i, jid := range jids {
+		resp[i] = BlockItem{JID: jid}
+	}
+	return resp, nil
+}
+
+// GetBlockList returns the JIDs blocked by a user. It can be called as
+// a user, for your own block list, or as an admin for any user. It
+// is only available on ejabberd servers exposing get_blocklist
+// command.
+func (c Client) GetBlockList(bareJID string) (BlockList, error) {
+	return c.GetBlockListContext(context.Background(), bareJID)
+}
+
+// GetBlockListContext is like GetBlockList but carries ctx into the
+// API call.
+func (c Client) GetBlockListContext(ctx context.Context, bareJID string) (BlockList, error) {
+	result, err := c.call(ctx, getBlockListRequest{JID: bareJID})
+	if err != nil {
+		return BlockList{}, err
+	}
+	resp := result.(BlockList)
+	return resp, nil
+}
+
+//==============================================================================
+
+// blockingStanza returns the XEP-0191 IQ stanza blocking or unblocking
+// jids.
+func blockingStanza(op string, jids []string) (string, error) {
+	type item struct {
+		JID string `xml:"jid,attr"`
+	}
+	type iq struct {
+		XMLName xml.Name `xml:"iq"`
+		Type    string   `xml:"type,attr"`
+		ID      string   `xml:"id,attr"`
+		Request struct {
+			XMLName xml.Name
+			Items   []item `xml:"item"`
+		}
+	}
+
+	stanza := iq{Type: "set", ID: op + "-" + strconv.FormatInt(time.Now().UnixNano(), 36)}
+	stanza.Request.XMLName = xml.Name{Space: "urn:xmpp:blocking", Local: op}
+	for _, jid := range jids {
+		if err := checkJID(jid); err != nil {
+			return "", err
+		}
+		stanza.Request.Items = append(stanza.Request.Items, item{JID: jid})
+	}
+
+	body, err := xml.Marshal(stanza)
+	return string(body), err
+}
+
+// BlockJIDs adds jids to the block list of a user. It requires admin
+// rights.
+//
+// The blocking request is sent as an IQ stanza on behalf of the user,
+// with send_stanza command. A successful result only means the stanza
+// was routed: it does not mean mod_blocking applied it. Use
+// GetBlockList to check the resulting block list.
+func (c Client) BlockJIDs(bareJID string, jids ...string) error {
+	return c.BlockJIDsContext(context.Background(), bareJID, jids...)
+}
+
+// BlockJIDsContext is like BlockJIDs but carries ctx into the API
+// call.
+func (c Client) BlockJIDsContext(ctx context.Context, bareJID string, jids ...string) error {
+	if _, err := parseJID(bareJID); err != nil {
+		return err
+	}
+	if len(jids) == 0 {
+		return fmt.Errorf("required jid to block not provided")
+	}
+	stanza, err := blockingStanza("block", jids)
+	if err != nil {
+		return err
+	}
+	return c.SendStanzaContext(ctx, bareJID, bareJID, stanza)
+}
+
+// UnblockJIDs removes jids from the block list of a user. It requires
+// admin rights. Use UnblockAll to empty the block list.
+//
+// As for BlockJIDs, a successful result from send_stanza command does
+// not mean mod_blocking applied the request.
+func (c Client) UnblockJIDs(bareJID string, jids ...string) error {
+	return c.UnblockJIDsContext(context.Background(), bareJID, jids...)
+}
+
+// UnblockJIDsContext is like UnblockJIDs but carries ctx into the API
+// call.
+func (c Client) UnblockJIDsContext(ctx context.Context, bareJID string, jids ...string) error {
+	if _, err := parseJID(bareJID); err != nil {
+		return err
+	}
+	if len(jids) == 0 {
+		return fmt.Errorf("required jid to unblock not provided")
+	}
+	stanza, err := blockingStanza("unblock", jids)
+	if err != nil {
+		return err
+	}
+	return c.SendStanzaContext(ctx, bareJID, bareJID, stanza)
+}
+
+// UnblockAll empties the block list of a user. It requires admin
+// rights.
+//
+// As for BlockJIDs, a successful result from send_stanza command does
+// not mean mod_blocking applied the request.
+func (c Client) UnblockAll(bareJID string) error {
+	return c.UnblockAllContext(context.Background(), bareJID)
+}
+
+// UnblockAllContext is like UnblockAll but carries ctx into the API
+// call.
+func (c Client) UnblockAllContext(ctx context.Context, bareJID string) error {
+	if _, err := parseJID(bareJID); err != nil {
+		return err
+	}
+	// An unblock request without item unblocks everyone.
+	stanza, err := blockingStanza("unblock", nil)
+	if err != nil {
+		return err
+	}
+	return c.SendStanzaContext(ctx, bareJID, bareJID, stanza)
+}
@@ -0,0 +1,96 @@
+package ejabberd_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/processone/ejabberd-api"
+)
+
+func Test_SetPrivacyList(t *testing.T) {
+	server, calls := newAPIServer(t, func(call apiCall) (int, string) {
+		return 200, `0`
+	})
+	defer server.Close()
+
+	client := ejabberd.Client{BaseURL: server.URL}
+
+	list := ejabberd.PrivacyList{Name: "quiet", Items: []ejabberd.PrivacyItem{
+		{Type: "jid", Value: "spammer@example.com", Action: "deny", Order: 1, Stanzas: []string{"message", "presence-in"}},
+		{Action: "allow", Order: 2},
+	}}
+	if err := client.SetPrivacyList("alice@localhost", list); err != nil {
+		t.Fatalf("SetPrivacyList failed: %s", err)
+	}
+	if err := client.SetPrivacyList("alice@localhost", ejabberd.PrivacyList{Name: "bad", Items: []ejabberd.PrivacyItem{{Action: "block"}}}); err == nil {
+		t.Errorf("SetPrivacyList accepted an unknown action")
+	}
+
+	checkCalls(t, *calls, []apiCall{
+		{command: "privacy_set", admin: true, args: map[string]interface{}{
+			"user": "alice", "host": "localhost",
+			"xmlquery": `<query xmlns="jabber:iq:privacy"><list name="quiet">` +
+				`<item type="jid" value="spammer@example.com" action="deny" order="1"><message></message><presence-in></presence-in></item>` +
+				`<item action="allow" order="2"></item></list></query>`,
+		}},
+	})
+}
+
+func Test_BlockJIDs(t *testing.T) {
+	server, calls := newAPIServer(t, func(call apiCall) (int, string) {
+		return 200, `0`
+	})
+	defer server.Close()
+
+	client := ejabberd.Client{BaseURL: server.URL}
+
+	if err := client.BlockJIDs("alice@localhost"); err == nil {
+		t.Errorf("BlockJIDs accepted an empty list of JIDs")
+	}
+	if err := client.BlockJIDs("alice@localhost", "spammer@example.com", "example.net"); err != nil {
+		t.Fatalf("BlockJIDs failed: %s", err)
+	}
+	if err := client.UnblockJIDs("alice@localhost"); err == nil {
+		t.Errorf("UnblockJIDs accepted an empty list of JIDs")
+	}
+	if err := client.UnblockJIDs("alice@localhost", "example.net"); err != nil {
+		t.Fatalf("UnblockJIDs failed: %s", err)
+	}
+	if err := client.UnblockAll("alice@localhost"); err != nil {
+		t.Fatalf("UnblockAll failed: %s", err)
+	}
+
+	if len(*calls) != 3 {
+		t.Fatalf("%d calls sent to server, want 3", len(*calls))
+	}
+	for i, want := range []string{
+		`<block xmlns="urn:xmpp:blocking"><item jid="spammer@example.com"></item><item jid="example.net"></item></block>`,
+		`<unblock xmlns="urn:xmpp:blocking"><item jid="example.net"></item></unblock>`,
+		`<unblock xmlns="urn:xmpp:blocking"></unblock>`,
+	} {
+		call := (*calls)[i]
+		stanza, _ := call.args["stanza"].(string)
+		if call.command != "send_stanza" || call.args["from"] != "alice@localhost" || call.args["to"] != "alice@localhost" ||
+			!strings.HasPrefix(stanza, `<iq type="set" id="`) || !strings.Contains(stanza, want) {
+			t.Errorf("Incorrect call %s %v", call.command, call.args)
+		}
+	}
+}
+
+func Test_GetBlockList(t *testing.T) {
+	server, _ := newAPIServer(t, func(call apiCall) (int, string) {
+		return 200, `["spammer@example.com","example.net"]`
+	})
+	defer server.Close()
+
+	client := ejabberd.Client{BaseURL: server.URL, Token: ejabberd.OAuthToken{JID: "alice@localhost"}}
+
+	list, err := client.GetBlockList("alice@localhost")
+	if err != nil {
+		t.Fatalf("GetBlockList failed: %s", err)
+	}
+	want := ejabberd.BlockList{{JID: "spammer@example.com"}, {JID: "example.net"}}
+	if list.JSON() != want.JSON() {
+		t.Errorf("GetBlockList = %s; want %s", list.JSON(), want.JSON())
+	}
+}
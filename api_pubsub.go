@@ -0,0 +1,466 @@
+package ejabberd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Publish-subscribe node commands. ejabberd mod_pubsub does not expose
+// all of them in every release: they are only available on servers
+// providing these commands, and calls fail with an APIError
+// otherwise.
+
+//==============================================================================
+
+// PubSubItem is an item published on a pubsub node.
+type PubSubItem struct {
+	ID        string `json:"id"`
+	Publisher string `json:"publisher,omitempty"`
+	Payload   string `json:"payload"` // XML element
+}
+
+// JSON represents PubSubItem as a JSON string, for further processing
+// with other tools.
+func (p PubSubItem) JSON() string {
+	body, _ := json.Marshal(p)
+	return string(body)
+}
+
+func (p PubSubItem) String() string {
+	return fmt.Sprintf("%s\t%s", p.ID, p.Payload)
+}
+
+// PubSubItems is a list of items of a pubsub node, as returned by
+// ejabberd get_items API.
+type PubSubItems []PubSubItem
+
+// JSON represents PubSubItems as a JSON array, for further processing
+// with other tools.
+func (p PubSubItems) JSON() string {
+	body, _ := json.Marshal(p)
+	return string(body)
+}
+
+// String represents PubSubItems with one item per line.
+func (p PubSubItems) String() string {
+	lines := make([]string, len(p))
+	for i, item := range p {
+		lines[i] = item.String()
+	}
+	return strings.Join(lines, "\n")
+}
+
+// PubSubNodes contains the names of the nodes of a pubsub service, as
+// returned by ejabberd list_nodes API.
+type PubSubNodes []string
+
+// JSON represents PubSubNodes as a JSON array, for further processing
+// with other tools.
+func (p PubSubNodes) JSON() string {
+	body, _ := json.Marshal(p)
+	return string(body)
+}
+
+// String represents PubSubNodes with one node per line.
+func (p PubSubNodes) String() string {
+	return strings.Join(p, "\n")
+}
+
+// PubSubSubscribers contains the JIDs subscribed to a pubsub node, as
+// returned by ejabberd get_subscribers API.
+type PubSubSubscribers []string
+
+// JSON represents PubSubSubscribers as a JSON array, for further
+// processing with other tools.
+func (p PubSubSubscribers) JSON() string {
+	body, _ := json.Marshal(p)
+	return string(body)
+}
+
+// String represents PubSubSubscribers with one JID per line.
+func (p PubSubSubscribers) String() string {
+	return strings.Join(p, "\n")
+}
+
+//==============================================================================
+
+// pubsubArgs are the arguments identifying a pubsub node.
+type pubsubArgs struct {
+	Service string `json:"service"`
+	Node    string `json:"node"`
+}
+
+func newPubSubArgs(service, node string) (pubsubArgs, error) {
+	if err := checkJID(service); err != nil {
+		return pubsubArgs{}, fmt.Errorf("invalid pubsub service: %s", service)
+	}
+	if node == "" {
+		return pubsubArgs{}, fmt.Errorf("required argument 'node' not provided")
+	}
+	return pubsubArgs{Service: service, Node: node}, nil
+}
+
+// pubsubNodeRequest is the request of pubsub commands acting on a node
+// without other argument.
+type pubsubNodeRequest struct {
+	Name    string `json:"-"`
+	Service string `json:"service"`
+	Node    string `json:"node"`
+}
+
+func (p pubsubNodeRequest) params() (apiParams, error) {
+	args, err := newPubSubArgs(p.Service, p.Node)
+	if err != nil {
+		return apiParams{}, err
+	}
+
+	params, err := commandParams(p.Name, 1, args)
+	params.admin = true
+	return params, err
+}
+
+func (p pubsubNodeRequest) parseResponse(body []byte) (Response, error) {
+	return parseAction(p.Name, body)
+}
+
+// pubsubJIDRequest is the request of pubsub commands acting on a node
+// on behalf of a JID.
+type pubsubJIDRequest struct {
+	Name    string `json:"-"`
+	Service string `json:"service"`
+	Node    string `json:"node"`
+	JID     string `json:"jid"`
+}
+
+func (p pubsubJIDRequest) params() (apiParams, error) {
+	args, err := newPubSubArgs(p.Service, p.Node)
+	if err != nil {
+		return apiParams{}, err
+	}
+	if err := checkJID(p.JID); err != nil {
+		return apiParams{}, err
+	}
+
+	type pubsubJID struct {
+		pubsubArgs
+		JID string `json:"jid"`
+	}
+
+	params, err := commandParams(p.Name, 1, pubsubJID{pubsubArgs: args, JID: p.JID})
+	params.admin = true
+	return params, err
+}
+
+func (p pubsubJIDRequest) parseResponse(body []byte) (Response, error) {
+	return parseAction(p.Name, body)
+}
+
+//==============================================================================
+
+// CreateNode creates a node on a pubsub service, like
+// pubsub.localhost, owned by ownerJID. It requires admin rights.
+func (c Client) CreateNode(service, node, ownerJID string) error {
+	return c.CreateNodeContext(context.Background(), service, node, ownerJID)
+}
+
+// CreateNodeContext is like CreateNode but carries ctx into the API
+// call.
+func (c Client) CreateNodeContext(ctx context.Context, service, node, ownerJID string) error {
+	_, err := c.call(ctx, pubsubJIDRequest{Name: "create_node", Service: service, Node: node, JID: ownerJID})
+	return err
+}
+
+// DeleteNode deletes a node and its items from a pubsub service. It
+// requires admin rights.
+func (c Client) DeleteNode(service, node string) error {
+	return c.DeleteNodeContext(context.Background(), service, node)
+}
+
+// DeleteNodeContext is like DeleteNode but carries ctx into the API
+// call.
+func (c Client) DeleteNodeContext(ctx context.Context, service, node string) error {
+	_, err := c.call(ctx, pubsubNodeRequest{Name: "delete_node", Service: service, Node: node})
+	return err
+}
+
+// PurgeNode removes all items of a node, keeping the node. It requires
+// admin rights.
+func (c Client) PurgeNode(service, node string) error {
+	return c.PurgeNodeContext(context.Background(), service, node)
+}
+
+// PurgeNodeContext is like PurgeNode but carries ctx into the API
+// call.
+func (c Client) PurgeNodeContext(ctx context.Context, service, node string) error {
+	_, err := c.call(ctx, pubsubNodeRequest{Name: "purge_node", Service: service, Node: node})
+	return err
+}
+
+// SubscribeNode subscribes a JID to the items published on a node. It
+// requires admin rights.
+func (c Client) SubscribeNode(service, node, jid string) error {
+	return c.SubscribeNodeContext(context.Background(), service, node, jid)
+}
+
+// SubscribeNodeContext is like SubscribeNode but carries ctx into the
+// API call.
+func (c Client) SubscribeNodeContext(ctx context.Context, service, node, jid string) error {
+	_, err := c.call(ctx, pubsubJIDRequest{Name: "subscribe_node", Service: service, Node: node, JID: jid})
+	return err
+}
+
+// UnsubscribeNode removes the subscription of a JID to a node. It
+// requires admin rights.
+func (c Client) UnsubscribeNode(service, node, jid string) error {
+	return c.UnsubscribeNodeContext(context.Background(), service, node, jid)
+}
+
+// UnsubscribeNodeContext is like UnsubscribeNode but carries ctx into
+// the API call.
+func (c Client) UnsubscribeNodeContext(ctx context.Context, service, node, jid string) error {
+	_, err := c.call(ctx, pubsubJIDRequest{Name: "unsubscribe_node", Service: service, Node: node, JID: jid})
+	return err
+}
+
+//==============================================================================
+
+type publishItemRequest struct {
+	Service   string     `json:"service"`
+	Node      string     `json:"node"`
+	Publisher string     `json:"publisher"`
+	Item      PubSubItem `json:"item"`
+}
+
+func (p publishItemRequest) params() (apiParams, error) {
+	args, err := newPubSubArgs(p.Service, p.Node)
+	if err != nil {
+		return apiParams{}, err
+	}
+	if err := checkJID(p.Publisher); err != nil {
+		return apiParams{}, err
+	}
+	if _, err := checkXML(p.Item.Payload); err != nil {
+		return apiParams{}, fmt.Errorf("invalid payload: %s", err)
+	}
+
+	type publishItem struct {
+		pubsubArgs
+		JID     string `json:"jid"`
+		ItemID  string `json:"itemid"`
+		Payload string `json:"payload"`
+	}
+
+	params, err := commandParams("publish_item", 1, publishItem{
+		pubsubArgs: args,
+		JID:        p.Publisher,
+		ItemID:     p.Item.ID,
+		Payload:    p.Item.Payload,
+	})
+	params.admin = true
+	return params, err
+}
+
+func (p publishItemRequest) parseResponse(body []byte) (Response, error) {
+	resp := PubSubItem{ID: p.Item.ID, Publisher: p.Publisher, Payload: p.Item.Payload}
+
+	// Servers return either the ID of the published item or a result
+	// code.
+	var id string
+	if err := json.Unmarshal(body, &id); err == nil {
+		if id != "" {
+			resp.ID = id
+		}
+		return resp, nil
+	}
+	if _, err := parseAction("publish_item", body); err != nil {
+		return PubSubItem{}, err
+	}
+	return resp, nil
+}
+
+// PublishItem publishes item on a node, on behalf of publisher. The
+// payload must be a single well-formed XML element. When item.ID is
+// empty, the server generates one. It returns the published item,
+// with its ID. It requires admin rights.
+func (c Client) PublishItem(service, node, publisher string, item PubSubItem) (PubSubItem, error) {
+	return c.PublishItemContext(context.Background(), service, node, publisher, item)
+}
+
+// PublishItemContext is like PublishItem but carries ctx into the API
+// call.
+func (c Client) PublishItemContext(ctx context.Context, service, node, publisher string, item PubSubItem) (PubSubItem, error) {
+	result, err := c.call(ctx, publishItemRequest{Service: service, Node: node, Publisher: publisher, Item: item})
+	if err != nil {
+		return PubSubItem{}, err
+	}
+	resp := result.(PubSubItem)
+	return resp, nil
+}
+
+//==============================================================================
+
+type getItemsRequest struct {
+	Service string `json:"service"`
+	Node    string `json:"node"`
+}
+
+func (g getItemsRequest) params() (apiParams, error) {
+	args, err := newPubSubArgs(g.Service, g.Node)
+	if err != nil {
+		return apiParams{}, err
+	}
+
+	p, err := commandParams("get_items", 1, args)
+	p.admin = true
+	p.idempotent = true
+	return p, err
+}
+
+func (g getItemsRequest) parseResponse(body []byte) (Response, error) {
+	var resp PubSubItems
+	err := decodeResponse("get_items", body, &resp)
+	return resp, err
+}
+
+// GetItems returns the items published on a node. It requires admin
+// rights.
+func (c Client) GetItems(service, node string) (PubSubItems, error) {
+	return c.GetItemsContext(context.Background(), service, node)
+}
+
+// GetItemsContext is like GetItems but carries ctx into the API call.
+func (c Client) GetItemsContext(ctx context.Context, service, node string) (PubSubItems, error) {
+	result, err := c.call(ctx, getItemsRequest{Service: service, Node: node})
+	if err != nil {
+		return PubSubItems{}, err
+	}
+	resp := result.(PubSubItems)
+	return resp, nil
+}
+
+//==============================================================================
+
+type deleteItemRequest struct {
+	Service string `json:"service"`
+	Node    string `json:"node"`
+	ItemID  string `json:"itemid"`
+}
+
+func (d deleteItemRequest) params() (apiParams, error) {
+	args, err := newPubSubArgs(d.Service, d.Node)
+	if err != nil {
+		return apiParams{}, err
+	}
+	if d.ItemID == "" {
+		return apiParams{}, fmt.Errorf("required argument 'itemid' not provided")
+	}
+
+	type deleteItem struct {
+		pubsubArgs
+		ItemID string `json:"itemid"`
+	}
+
+	p, err := commandParams("delete_item", 1, deleteItem{pubsubArgs: args, ItemID: d.ItemID})
+	p.admin = true
+	return p, err
+}
+
+func (d deleteItemRequest) parseResponse(body []byte) (Response, error) {
+	return parseAction("delete_item", body)
+}
+
+// DeleteItem retracts an item from a node. It requires admin rights.
+func (c Client) DeleteItem(service, node, itemID string) error {
+	return c.DeleteItemContext(context.Background(), service, node, itemID)
+}
+
+// DeleteItemContext is like DeleteItem but carries ctx into the API
+// call.
+func (c Client) DeleteItemContext(ctx context.Context, service, node, itemID string) error {
+	_, err := c.call(ctx, deleteItemRequest{Service: service, Node: node, ItemID: itemID})
+	return err
+}
+
+//==============================================================================
+
+type getSubscribersRequest struct {
+	Service string `json:"service"`
+	Node    string `json:"node"`
+}
+
+func (g getSubscribersRequest) params() (apiParams, error) {
+	args, err := newPubSubArgs(g.Service, g.Node)
+	if err != nil {
+		return apiParams{}, err
+	}
+
+	p, err := commandParams("get_subscribers", 1, args)
+	p.admin = true
+	p.idempotent = true
+	return p, err
+}
+
+func (g getSubscribersRequest) parseResponse(body []byte) (Response, error) {
+	var resp PubSubSubscribers
+	err := decodeResponse("get_subscribers", body, &resp)
+	return resp, err
+}
+
+// GetSubscribers returns the JIDs subscribed to a node. It requires
+// admin rights.
+func (c Client) GetSubscribers(service, node string) (PubSubSubscribers, error) {
+	return c.GetSubscribersContext(context.Background(), service, node)
+}
+
+// GetSubscribersContext is like GetSubscribers but carries ctx into
+// the API call.
+func (c Client) GetSubscribersContext(ctx context.Context, service, node string) (PubSubSubscribers, error) {
+	result, err := c.call(ctx, getSubscribersRequest{Service: service, Node: node})
+	if err != nil {
+		return PubSubSubscribers{}, err
+	}
+	resp := result.(PubSubSubscribers)
+	return resp, nil
+}
+
+//==============================================================================
+
+type listNodesRequest struct {
+	Service string `json:"service"`
+}
+
+func (l listNodesRequest) params() (apiParams, error) {
+	if err := checkJID(l.Service); err != nil {
+		return apiParams{}, fmt.Errorf("invalid pubsub service: %s", l.Service)
+	}
+
+	p, err := commandParams("list_nodes", 1, l)
+	p.admin = true
+	p.idempotent = true
+	return p, err
+}
+
+func (l listNodesRequest) parseResponse(body []byte) (Response, error) {
+	var resp PubSubNodes
+	err := decodeResponse("list_nodes", body, &resp)
+	return resp, err
+}
+
+// ListNodes returns the names of the nodes of a pubsub service. It
+// requires admin rights.
+func (c Client) ListNodes(service string) (PubSubNodes, error) {
+	return c.ListNodesContext(context.Background(), service)
+}
+
+// ListNodesContext is like ListNodes but carries ctx into the API
+// call.
+func (c Client) ListNodesContext(ctx context.Context, service string) (PubSubNodes, error) {
+	result, err := c.call(ctx, listNodesRequest{Service: service})
+	if err != nil {
+		return PubSubNodes{}, err
+	}
+	resp := result.(PubSubNodes)
+	return resp, nil
+}
@@ -0,0 +1,65 @@
+package ejabberd_test
+
+import (
+	"testing"
+
+	"github.com/processone/ejabberd-api"
+)
+
+func Test_PublishItem(t *testing.T) {
+	server, calls := newAPIServer(t, func(call apiCall) (int, string) {
+		if call.args["itemid"] == "" {
+			return 200, `"5f3c2a"`
+		}
+		return 200, `0`
+	})
+	defer server.Close()
+
+	client := ejabberd.Client{BaseURL: server.URL}
+
+	payloads := []string{``, `flag`, `<flag>`, `<flag/><flag/>`}
+	for _, payload := range payloads {
+		item := ejabberd.PubSubItem{Payload: payload}
+		if _, err := client.PublishItem("pubsub.localhost", "flags", "admin@localhost", item); err == nil {
+			t.Errorf("PublishItem accepted payload %q", payload)
+		}
+	}
+
+	item := ejabberd.PubSubItem{Payload: `<flag xmlns="urn:example:flags" name="beta">on</flag>`}
+	published, err := client.PublishItem("pubsub.localhost", "flags", "admin@localhost", item)
+	if err != nil || published.ID != "5f3c2a" {
+		t.Errorf("PublishItem = %+v, %v", published, err)
+	}
+	item.ID = "beta"
+	published, err = client.PublishItem("pubsub.localhost", "flags", "admin@localhost", item)
+	if err != nil || published.ID != "beta" {
+		t.Errorf("PublishItem = %+v, %v", published, err)
+	}
+
+	checkCalls(t, *calls, []apiCall{
+		{command: "publish_item", admin: true, args: map[string]interface{}{
+			"service": "pubsub.localhost", "node": "flags", "jid": "admin@localhost", "itemid": "", "payload": item.Payload,
+		}},
+		{command: "publish_item", admin: true, args: map[string]interface{}{
+			"service": "pubsub.localhost", "node": "flags", "jid": "admin@localhost", "itemid": "beta", "payload": item.Payload,
+		}},
+	})
+}
+
+func Test_GetItems(t *testing.T) {
+	server, _ := newAPIServer(t, func(call apiCall) (int, string) {
+		return 200, `[{"id":"beta","publisher":"admin@localhost","payload":"<flag name='beta'>on</flag>"}]`
+	})
+	defer server.Close()
+
+	client := ejabberd.Client{BaseURL: server.URL}
+
+	items, err := client.GetItems("pubsub.localhost", "flags")
+	if err != nil {
+		t.Fatalf("GetItems failed: %s", err)
+	}
+	want := ejabberd.PubSubItems{{ID: "beta", Publisher: "admin@localhost", Payload: "<flag name='beta'>on</flag>"}}
+	if items.JSON() != want.JSON() {
+		t.Errorf("GetItems = %s; want %s", items.JSON(), want.JSON())
+	}
+}
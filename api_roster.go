@@ -0,0 +1,324 @@
+package ejabberd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Roster management commands, from mod_admin_extra.
+
+//==============================================================================
+
+// RosterItem is a contact in a user roster.
+type RosterItem struct {
+	JID          string   `json:"jid"`
+	Nick         string   `json:"nick"`
+	Subscription string   `json:"subscription"` // none, from, to or both
+	Ask          string   `json:"ask"`          // none, in, out or both
+	Groups       []string `json:"groups"`
+}
+
+// JSON represents RosterItem as a JSON string, for further processing
+// with other tools.
+func (r RosterItem) JSON() string {
+	body, _ := json.Marshal(r)
+	return string(body)
+}
+
+func (r RosterItem) String() string {
+	return fmt.Sprintf("%s\t%s\t%s\t%s", r.JID, r.Nick, r.Subscription, strings.Join(r.Groups, ","))
+}
+
+// UnmarshalJSON decodes roster items in both formats returned by
+// ejabberd: API version 0 sends a single group, later versions send a
+// list of groups.
+func (r *RosterItem) UnmarshalJSON(data []byte) error {
+	type rosterItem RosterItem
+	var item struct {
+		rosterItem
+		Group string `json:"group"`
+	}
+	if err := json.Unmarshal(data, &item); err != nil {
+		return err
+	}
+	*r = RosterItem(item.rosterItem)
+	if r.Groups == nil && item.Group != "" {
+		r.Groups = []string{item.Group}
+	}
+	return nil
+}
+
+// Roster is the list of contacts of a user, as returned by ejabberd
+// get_roster API.
+type Roster []RosterItem
+
+// JSON represents Roster as a JSON array, for further processing with
+// other tools.
+func (r Roster) JSON() string {
+	body, _ := json.Marshal(r)
+	return string(body)
+}
+
+// String represents Roster with one contact per line.
+func (r Roster) String() string {
+	lines := make([]string, len(r))
+	for i, item := range r {
+		lines[i] = item.String()
+	}
+	return strings.Join(lines, "\n")
+}
+
+//==============================================================================
+
+type getRosterRequest struct {
+	JID string `json:"jid"`
+}
+
+func (g getRosterRequest) params() (apiParams, error) {
+	jid, err := parseJID(g.JID)
+	if err != nil {
+		return apiParams{}, err
+	}
+
+	type getRoster struct {
+		User string `json:"user"`
+		Host string `json:"host"`
+	}
+
+	p, err := commandParams("get_roster", 1, getRoster{
+		User: jid.username,
+		Host: jid.domain,
+	})
+	p.idempotent = true
+	return p, err
+}
+
+func (g getRosterRequest) parseResponse(body []byte) (Response, error) {
+	var resp Roster
+	err := decodeResponse("get_roster", body, &resp)
+	return resp, err
+}
+
+// GetRoster returns the contacts of a user. It can be called as a
+// user, to read your own roster, or as an admin to read the roster of
+// any user.
+func (c Client) GetRoster(bareJID string) (Roster, error) {
+	return c.GetRosterContext(context.Background(), bareJID)
+}
+
+// GetRosterContext is like GetRoster but carries ctx into the API
+// call.
+func (c Client) GetRosterContext(ctx context.Context, bareJID string) (Roster, error) {
+	result, err := c.call(ctx, getRosterRequest{JID: bareJID})
+	if err != nil {
+		return Roster{}, err
+	}
+	resp := result.(Roster)
+	return resp, nil
+}
+
+//==============================================================================
+
+type addRosterItemRequest struct {
+	JID  string     `json:"jid"`
+	Item RosterItem `json:"item"`
+}
+
+func (a addRosterItemRequest) params() (apiParams, error) {
+	owner, err := parseJID(a.JID)
+	if err != nil {
+		return apiParams{}, err
+	}
+	contact, err := parseJID(a.Item.JID)
+	if err != nil {
+		return apiParams{}, err
+	}
+
+	subscription := a.Item.Subscription
+	switch subscription {
+	case "":
+		subscription = "both"
+	case "none", "from", "to", "both":
+	default:
+		return apiParams{}, fmt.Errorf("unknown subscription: %s", subscription)
+	}
+
+	groups := a.Item.Groups
+	if groups == nil {
+		groups = []string{}
+	}
+
+	type addRosterItem struct {
+		LocalUser string   `json:"localuser"`
+		LocalHost string   `json:"localhost"`
+		User      string   `json:"user"`
+		Host      string   `json:"host"`
+		Nick      string   `json:"nick"`
+		Groups    []string `json:"groups"`
+		Subs      string   `json:"subs"`
+	}
+
+	return commandParams("add_rosteritem", 1, addRosterItem{
+		LocalUser: owner.username,
+		LocalHost: owner.domain,
+		User:      contact.username,
+		Host:      contact.domain,
+		Nick:      a.Item.Nick,
+		Groups:    groups,
+		Subs:      subscription,
+	})
+}
+
+func (a addRosterItemRequest) parseResponse(body []byte) (Response, error) {
+	return parseAction("add_rosteritem", body)
+}
+
+// AddRosterItem adds a contact to the roster of a user, or updates it
+// if it already exists. Subscription defaults to both. Ask is
+// ignored.
+func (c Client) AddRosterItem(bareJID string, item RosterItem) error {
+	return c.AddRosterItemContext(context.Background(), bareJID, item)
+}
+
+// AddRosterItemContext is like AddRosterItem but carries ctx into the
+// API call.
+func (c Client) AddRosterItemContext(ctx context.Context, bareJID string, item RosterItem) error {
+	_, err := c.call(ctx, addRosterItemRequest{JID: bareJID, Item: item})
+	return err
+}
+
+//==============================================================================
+
+type deleteRosterItemRequest struct {
+	JID     string `json:"jid"`
+	Contact string `json:"contact"`
+}
+
+func (d deleteRosterItemRequest) params() (apiParams, error) {
+	owner, err := parseJID(d.JID)
+	if err != nil {
+		return apiParams{}, err
+	}
+	contact, err := parseJID(d.Contact)
+	if err != nil {
+		return apiParams{}, err
+	}
+
+	type deleteRosterItem struct {
+		LocalUser string `json:"localuser"`
+		LocalHost string `json:"localhost"`
+		User      string `json:"user"`
+		Host      string `json:"host"`
+	}
+
+	return commandParams("delete_rosteritem", 1, deleteRosterItem{
+		LocalUser: owner.username,
+		LocalHost: owner.domain,
+		User:      contact.username,
+		Host:      contact.domain,
+	})
+}
+
+func (d deleteRosterItemRequest) parseResponse(body []byte) (Response, error) {
+	return parseAction("delete_rosteritem", body)
+}
+
+// DeleteRosterItem removes contact from the roster of a user.
+func (c Client) DeleteRosterItem(bareJID, contact string) error {
+	return c.DeleteRosterItemContext(context.Background(), bareJID, contact)
+}
+
+// DeleteRosterItemContext is like DeleteRosterItem but carries ctx
+// into the API call.
+func (c Client) DeleteRosterItemContext(ctx context.Context, bareJID, contact string) error {
+	_, err := c.call(ctx, deleteRosterItemRequest{JID: bareJID, Contact: contact})
+	return err
+}
+
+//==============================================================================
+
+type pushRosterRequest struct {
+	JID  string `json:"jid"`
+	File string `json:"file"`
+}
+
+func (p pushRosterRequest) params() (apiParams, error) {
+	jid, err := parseJID(p.JID)
+	if err != nil {
+		return apiParams{}, err
+	}
+	if p.File == "" {
+		return apiParams{}, fmt.Errorf("required argument 'file' not provided")
+	}
+
+	type pushRoster struct {
+		File string `json:"file"`
+		User string `json:"user"`
+		Host string `json:"host"`
+	}
+
+	params, err := commandParams("push_roster", 1, pushRoster{
+		File: p.File,
+		User: jid.username,
+		Host: jid.domain,
+	})
+	params.admin = true
+	return params, err
+}
+
+func (p pushRosterRequest) parseResponse(body []byte) (Response, error) {
+	return parseAction("push_roster", body)
+}
+
+// PushRoster pushes the contacts listed in file to the roster of a
+// user. The file is read by ejabberd and must be available on the
+// server node. It requires admin rights.
+func (c Client) PushRoster(bareJID, file string) error {
+	return c.PushRosterContext(context.Background(), bareJID, file)
+}
+
+// PushRosterContext is like PushRoster but carries ctx into the API
+// call.
+func (c Client) PushRosterContext(ctx context.Context, bareJID, file string) error {
+	_, err := c.call(ctx, pushRosterRequest{JID: bareJID, File: file})
+	return err
+}
+
+//==============================================================================
+
+type pushAllToAllRequest struct {
+	Host  string `json:"host"`
+	Group string `json:"group"`
+}
+
+func (p pushAllToAllRequest) params() (apiParams, error) {
+	if p.Host == "" {
+		return apiParams{}, fmt.Errorf("required argument 'host' not provided")
+	}
+	if p.Group == "" {
+		return apiParams{}, fmt.Errorf("required argument 'group' not provided")
+	}
+
+	params, err := commandParams("push_alltoall", 1, p)
+	params.admin = true
+	return params, err
+}
+
+func (p pushAllToAllRequest) parseResponse(body []byte) (Response, error) {
+	return parseAction("push_alltoall", body)
+}
+
+// PushAllToAll adds all the users of a virtual host to the rosters of
+// each other, in the given group. It requires admin rights.
+func (c Client) PushAllToAll(host, group string) error {
+	return c.PushAllToAllContext(context.Background(), host, group)
+}
+
+// PushAllToAllContext is like PushAllToAll but carries ctx into the
+// API call.
+func (c Client) PushAllToAllContext(ctx context.Context, host, group string) error {
+	_, err := c.call(ctx, pushAllToAllRequest{Host: host, Group: group})
+	return err
+}
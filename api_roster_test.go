@@ -0,0 +1,69 @@
+package ejabberd_test
+
+import (
+	"testing"
+
+	"github.com/processone/ejabberd-api"
+)
+
+func Test_Roster(t *testing.T) {
+	server, calls := newAPIServer(t, func(call apiCall) (int, string) {
+		if call.command == "get_roster" {
+			return 200, `[{"jid":"bob@localhost","nick":"Bob","subscription":"both","ask":"none","groups":["Friends","Work"]},
+			              {"jid":"carol@localhost","nick":"Carol","subscription":"to","ask":"none","group":"Family"}]`
+		}
+		return 200, `0`
+	})
+	defer server.Close()
+
+	client := ejabberd.Client{BaseURL: server.URL, Token: ejabberd.OAuthToken{JID: "alice@localhost"}}
+
+	roster, err := client.GetRoster("alice@localhost")
+	if err != nil {
+		t.Fatalf("GetRoster failed: %s", err)
+	}
+	if len(roster) != 2 || len(roster[0].Groups) != 2 || roster[1].Groups[0] != "Family" {
+		t.Errorf("Incorrect roster %+v", roster)
+	}
+
+	item := ejabberd.RosterItem{JID: "dave@example.com", Nick: "Dave", Groups: []string{"Work"}}
+	if err := client.AddRosterItem("alice@localhost", item); err != nil {
+		t.Errorf("AddRosterItem failed: %s", err)
+	}
+	item.Subscription = "unknown"
+	if err := client.AddRosterItem("alice@localhost", item); err == nil {
+		t.Errorf("AddRosterItem should fail on unknown subscription")
+	}
+	if err := client.DeleteRosterItem("bob@localhost", "alice@localhost"); err != nil {
+		t.Errorf("DeleteRosterItem failed: %s", err)
+	}
+
+	checkCalls(t, *calls, []apiCall{
+		{"get_roster", false, map[string]interface{}{"user": "alice", "host": "localhost"}},
+		{"add_rosteritem", false, map[string]interface{}{
+			"localuser": "alice", "localhost": "localhost", "user": "dave", "host": "example.com",
+			"nick": "Dave", "groups": []interface{}{"Work"}, "subs": "both"}},
+		{"delete_rosteritem", true, map[string]interface{}{
+			"localuser": "bob", "localhost": "localhost", "user": "alice", "host": "localhost"}},
+	})
+}
+
+func Test_PushRoster(t *testing.T) {
+	server, calls := newAPIServer(t, func(call apiCall) (int, string) {
+		return 200, `0`
+	})
+	defer server.Close()
+
+	client := ejabberd.Client{BaseURL: server.URL}
+
+	if err := client.PushRoster("alice@localhost", "/var/lib/ejabberd/roster.txt"); err != nil {
+		t.Fatalf("PushRoster failed: %s", err)
+	}
+	if err := client.PushRoster("alice@localhost", ""); err == nil {
+		t.Errorf("PushRoster accepted an empty file")
+	}
+
+	checkCalls(t, *calls, []apiCall{
+		{"push_roster", true, map[string]interface{}{"user": "alice", "host": "localhost", "file": "/var/lib/ejabberd/roster.txt"}},
+	})
+}
@@ -0,0 +1,218 @@
+package ejabberd
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Message and stanza sending commands, from mod_admin_extra.
+
+//==============================================================================
+
+// OutgoingMessage is a message to send with SendMessage.
+type OutgoingMessage struct {
+	Type    string `json:"type"` // chat, normal or headline. Defaults to normal.
+	From    string `json:"from"`
+	To      string `json:"to"`
+	Subject string `json:"subject"`
+	Body    string `json:"body"`
+}
+
+type sendMessageRequest struct {
+	Message OutgoingMessage `json:"message"`
+}
+
+func (s sendMessageRequest) params() (apiParams, error) {
+	m := s.Message
+	switch m.Type {
+	case "":
+		m.Type = "normal"
+	case "chat", "normal", "headline":
+	default:
+		return apiParams{}, fmt.Errorf("unknown message type: %s", m.Type)
+	}
+	if err := checkJID(m.From); err != nil {
+		return apiParams{}, err
+	}
+	if err := checkJID(m.To); err != nil {
+		return apiParams{}, err
+	}
+
+	p, err := commandParams("send_message", 1, m)
+	p.admin = true
+	return p, err
+}
+
+func (s sendMessageRequest) parseResponse(body []byte) (Response, error) {
+	return parseAction("send_message", body)
+}
+
+// SendMessage sends a message on behalf of any JID, including the
+// server JID, for example to push system notifications. It requires
+// admin rights.
+func (c Client) SendMessage(m OutgoingMessage) error {
+	return c.SendMessageContext(context.Background(), m)
+}
+
+// SendMessageContext is like SendMessage but carries ctx into the API
+// call.
+func (c Client) SendMessageContext(ctx context.Context, m OutgoingMessage) error {
+	_, err := c.call(ctx, sendMessageRequest{Message: m})
+	return err
+}
+
+//==============================================================================
+
+type sendStanzaRequest struct {
+	From   string `json:"from"`
+	To     string `json:"to"`
+	Stanza string `json:"stanza"`
+}
+
+func (s sendStanzaRequest) params() (apiParams, error) {
+	if err := checkJID(s.From); err != nil {
+		return apiParams{}, err
+	}
+	if err := checkJID(s.To); err != nil {
+		return apiParams{}, err
+	}
+	if err := checkStanza(s.Stanza); err != nil {
+		return apiParams{}, err
+	}
+
+	p, err := commandParams("send_stanza", 1, s)
+	p.admin = true
+	return p, err
+}
+
+func (s sendStanzaRequest) parseResponse(body []byte) (Response, error) {
+	return parseAction("send_stanza", body)
+}
+
+// SendStanza routes a raw XML stanza from a JID to another one. The
+// stanza is checked to be well-formed XML before being sent. It
+// requires admin rights.
+func (c Client) SendStanza(from, to, stanza string) error {
+	return c.SendStanzaContext(context.Background(), from, to, stanza)
+}
+
+// SendStanzaContext is like SendStanza but carries ctx into the API
+// call.
+func (c Client) SendStanzaContext(ctx context.Context, from, to, stanza string) error {
+	_, err := c.call(ctx, sendStanzaRequest{From: from, To: to, Stanza: stanza})
+	return err
+}
+
+//==============================================================================
+
+type sendStanzaC2SRequest struct {
+	JID    string `json:"jid"`
+	Stanza string `json:"stanza"`
+}
+
+func (s sendStanzaC2SRequest) params() (apiParams, error) {
+	jid, err := parseJID(s.JID)
+	if err != nil {
+		return apiParams{}, err
+	}
+	if jid.resource == "" {
+		return apiParams{}, fmt.Errorf("full jid with resource required: %s", s.JID)
+	}
+	if err := checkStanza(s.Stanza); err != nil {
+		return apiParams{}, err
+	}
+
+	type sendStanzaC2S struct {
+		User     string `json:"user"`
+		Host     string `json:"host"`
+		Resource string `json:"resource"`
+		Stanza   string `json:"stanza"`
+	}
+
+	p, err := commandParams("send_stanza_c2s", 1, sendStanzaC2S{
+		User:     jid.username,
+		Host:     jid.domain,
+		Resource: jid.resource,
+		Stanza:   s.Stanza,
+	})
+	p.admin = true
+	return p, err
+}
+
+func (s sendStanzaC2SRequest) parseResponse(body []byte) (Response, error) {
+	return parseAction("send_stanza_c2s", body)
+}
+
+// SendStanzaC2S sends a raw XML stanza as if it was sent by the
+// client session of a full JID (user@domain/resource). The stanza is
+// checked to be well-formed XML before being sent. It requires admin
+// rights.
+func (c Client) SendStanzaC2S(fullJID, stanza string) error {
+	return c.SendStanzaC2SContext(context.Background(), fullJID, stanza)
+}
+
+// SendStanzaC2SContext is like SendStanzaC2S but carries ctx into the
+// API call.
+func (c Client) SendStanzaC2SContext(ctx context.Context, fullJID, stanza string) error {
+	_, err := c.call(ctx, sendStanzaC2SRequest{JID: fullJID, Stanza: stanza})
+	return err
+}
+
+//==============================================================================
+
+// checkStanza checks that stanza is a well-formed XML document with a
+// single message, presence or iq root element.
+func checkStanza(stanza string) error {
+	root, err := checkXML(stanza)
+	if err != nil {
+		return fmt.Errorf("invalid stanza: %s", err)
+	}
+	switch root.Local {
+	case "message", "presence", "iq":
+		return nil
+	}
+	return fmt.Errorf("invalid stanza: unexpected root element <%s>", root.Local)
+}
+
+// checkXML checks that s is a well-formed XML fragment with a single
+// root element, and returns the name of that element.
+func checkXML(s string) (xml.Name, error) {
+	var root xml.Name
+	depth := 0
+	dec := xml.NewDecoder(strings.NewReader(s))
+	for {
+		tok, err := dec.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return root, err
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			if depth == 0 && root.Local != "" {
+				return root, fmt.Errorf("more than one root element")
+			}
+			if depth == 0 {
+				root = t.Name
+			}
+			depth++
+		case xml.EndElement:
+			depth--
+		case xml.CharData:
+			if depth == 0 && strings.TrimSpace(string(t)) != "" {
+				return root, fmt.Errorf("text outside root element")
+			}
+		}
+	}
+
+	if root.Local == "" {
+		return root, fmt.Errorf("no root element")
+	}
+	return root, nil
+}
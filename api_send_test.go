@@ -0,0 +1,90 @@
+package ejabberd_test
+
+import (
+	"testing"
+
+	"github.com/processone/ejabberd-api"
+)
+
+func Test_SendMessage(t *testing.T) {
+	server, calls := newAPIServer(t, func(call apiCall) (int, string) {
+		return 200, `0`
+	})
+	defer server.Close()
+
+	client := ejabberd.Client{BaseURL: server.URL}
+
+	err := client.SendMessage(ejabberd.OutgoingMessage{
+		From: "localhost",
+		To:   "alice@localhost",
+		Body: "Maintenance tonight",
+	})
+	if err != nil {
+		t.Fatalf("SendMessage failed: %s", err)
+	}
+
+	checkCalls(t, *calls, []apiCall{
+		{command: "send_message", admin: true, args: map[string]interface{}{
+			"type": "normal", "from": "localhost", "to": "alice@localhost", "subject": "", "body": "Maintenance tonight",
+		}},
+	})
+}
+
+func Test_SendStanzaValidation(t *testing.T) {
+	server, calls := newAPIServer(t, func(call apiCall) (int, string) {
+		return 200, `0`
+	})
+	defer server.Close()
+
+	client := ejabberd.Client{BaseURL: server.URL}
+
+	var tests = []struct {
+		from, to, stanza string
+		valid            bool
+	}{
+		{"localhost", "alice@localhost", `<message><body>Hi</body></message>`, true},
+		{"bob@localhost/phone", "alice@localhost", ` <presence type="probe"/> `, true},
+		{"localhost", "alice@localhost", `<message><body>Hi</message>`, false},
+		{"localhost", "alice@localhost", `<message/><message/>`, false},
+		{"localhost", "alice@localhost", `<body>Hi</body>`, false},
+		{"localhost", "alice@localhost", `Hi`, false},
+		{"@localhost", "alice@localhost", `<message/>`, false},
+		{"localhost", "", `<message/>`, false},
+	}
+
+	sent := 0
+	for _, test := range tests {
+		err := client.SendStanza(test.from, test.to, test.stanza)
+		if (err == nil) != test.valid {
+			t.Errorf("SendStanza(%q, %q, %q) = %v", test.from, test.to, test.stanza, err)
+		}
+		if test.valid {
+			sent++
+		}
+	}
+	if len(*calls) != sent {
+		t.Errorf("%d calls sent to server, want %d", len(*calls), sent)
+	}
+}
+
+func Test_SendStanzaC2S(t *testing.T) {
+	server, calls := newAPIServer(t, func(call apiCall) (int, string) {
+		return 200, `0`
+	})
+	defer server.Close()
+
+	client := ejabberd.Client{BaseURL: server.URL}
+
+	if err := client.SendStanzaC2S("alice@localhost", `<presence/>`); err == nil {
+		t.Errorf("SendStanzaC2S accepted a bare JID")
+	}
+	if err := client.SendStanzaC2S("alice@localhost/phone", `<presence/>`); err != nil {
+		t.Fatalf("SendStanzaC2S failed: %s", err)
+	}
+
+	checkCalls(t, *calls, []apiCall{
+		{command: "send_stanza_c2s", admin: true, args: map[string]interface{}{
+			"user": "alice", "host": "localhost", "resource": "phone", "stanza": "<presence/>",
+		}},
+	})
+}
@@ -0,0 +1,279 @@
+package ejabberd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"time"
+)
+
+// Server status and node lifecycle commands, from ejabberd_admin.
+
+//==============================================================================
+
+// ServerStatus contains the result of the call to ejabberd status API.
+type ServerStatus struct {
+	Node    string `json:"node"`
+	Status  string `json:"status"` // Erlang node status, like started
+	Running bool   `json:"running"`
+	Version string `json:"version,omitempty"`
+	Message string `json:"message"` // Status message returned by ejabberd
+}
+
+// JSON represents ServerStatus as a JSON string, for further
+// processing with other tools.
+func (s ServerStatus) JSON() string {
+	body, _ := json.Marshal(s)
+	return string(body)
+}
+
+func (s ServerStatus) String() string {
+	return s.Message
+}
+
+// statusRegexp matches status messages returned by ejabberd, like:
+// The node ejabberd@localhost is started with status: started
+// ejabberd 23.10 is running in that node
+var statusRegexp = regexp.MustCompile(`^The node (\S+) is (\S+) with status: (\S+)\s*(ejabberd (\S+) is running in that node)?`)
+
+type statusRequest struct{}
+
+func (s statusRequest) params() (apiParams, error) {
+	p, err := commandParams("status", 1, s)
+	p.admin = true
+	p.idempotent = true
+	return p, err
+}
+
+func (s statusRequest) parseResponse(body []byte) (Response, error) {
+	resp := ServerStatus{}
+	if err := decodeResponse("status", body, &resp.Message); err != nil {
+		return ServerStatus{}, err
+	}
+
+	if m := statusRegexp.FindStringSubmatch(resp.Message); m != nil {
+		resp.Node = m[1]
+		resp.Status = m[3]
+		resp.Running = m[4] != ""
+		resp.Version = m[5]
+	}
+	return resp, nil
+}
+
+// Status returns the status of the ejabberd node serving the API. It
+// requires admin rights.
+func (c Client) Status() (ServerStatus, error) {
+	return c.StatusContext(context.Background())
+}
+
+// StatusContext is like Status but carries ctx into the API call.
+func (c Client) StatusContext(ctx context.Context) (ServerStatus, error) {
+	result, err := c.call(ctx, statusRequest{})
+	if err != nil {
+		return ServerStatus{}, err
+	}
+	resp := result.(ServerStatus)
+	return resp, nil
+}
+
+//==============================================================================
+
+// serverActionRequest is the request of lifecycle commands without
+// arguments.
+type serverActionRequest struct {
+	Name string `json:"-"`
+}
+
+func (s serverActionRequest) params() (apiParams, error) {
+	p, err := commandParams(s.Name, 1, s)
+	p.admin = true
+	return p, err
+}
+
+func (s serverActionRequest) parseResponse(body []byte) (Response, error) {
+	return parseAction(s.Name, body)
+}
+
+// Stop stops the ejabberd node serving the API. The connection may be
+// closed before a response is received, in which case a
+// TransportError is returned. It requires admin rights.
+func (c Client) Stop() error {
+	return c.StopContext(context.Background())
+}
+
+// StopContext is like Stop but carries ctx into the API call.
+func (c Client) StopContext(ctx context.Context) error {
+	_, err := c.call(ctx, serverActionRequest{Name: "stop"})
+	return err
+}
+
+// Restart restarts the ejabberd node serving the API. Like Stop, it
+// can return a TransportError when the connection is closed before a
+// response is received. It requires admin rights.
+func (c Client) Restart() error {
+	return c.RestartContext(context.Background())
+}
+
+// RestartContext is like Restart but carries ctx into the API call.
+func (c Client) RestartContext(ctx context.Context) error {
+	_, err := c.call(ctx, serverActionRequest{Name: "restart"})
+	return err
+}
+
+// ReopenLog reopens the log files, after they have been rotated. It
+// requires admin rights.
+func (c Client) ReopenLog() error {
+	return c.ReopenLogContext(context.Background())
+}
+
+// ReopenLogContext is like ReopenLog but carries ctx into the API
+// call.
+func (c Client) ReopenLogContext(ctx context.Context) error {
+	_, err := c.call(ctx, serverActionRequest{Name: "reopen_log"})
+	return err
+}
+
+// ReloadConfig reloads the configuration file of the ejabberd node. It
+// requires admin rights.
+func (c Client) ReloadConfig() error {
+	return c.ReloadConfigContext(context.Background())
+}
+
+// ReloadConfigContext is like ReloadConfig but carries ctx into the
+// API call.
+func (c Client) ReloadConfigContext(ctx context.Context) error {
+	_, err := c.call(ctx, serverActionRequest{Name: "reload_config"})
+	return err
+}
+
+//==============================================================================
+
+// LogLevels are the log levels supported by ejabberd, from the least
+// to the most verbose.
+var LogLevels = []string{"none", "emergency", "alert", "critical", "error", "warning", "notice", "info", "debug"}
+
+// LogLevel contains the result of the call to ejabberd get_loglevel
+// API.
+type LogLevel struct {
+	Level string `json:"level"`
+}
+
+// JSON represents LogLevel as a JSON string, for further processing
+// with other tools.
+func (l LogLevel) JSON() string {
+	body, _ := json.Marshal(l)
+	return string(body)
+}
+
+func (l LogLevel) String() string {
+	return l.Level
+}
+
+type getLogLevelRequest struct{}
+
+func (g getLogLevelRequest) params() (apiParams, error) {
+	p, err := commandParams("get_loglevel", 1, g)
+	p.admin = true
+	p.idempotent = true
+	return p, err
+}
+
+func (g getLogLevelRequest) parseResponse(body []byte) (Response, error) {
+	var resp LogLevel
+	err := decodeResponse("get_loglevel", body, &resp.Level)
+	return resp, err
+}
+
+// GetLogLevel returns the current log level of the ejabberd node. It
+// requires admin rights.
+func (c Client) GetLogLevel() (LogLevel, error) {
+	return c.GetLogLevelContext(context.Background())
+}
+
+// GetLogLevelContext is like GetLogLevel but carries ctx into the API
+// call.
+func (c Client) GetLogLevelContext(ctx context.Context) (LogLevel, error) {
+	result, err := c.call(ctx, getLogLevelRequest{})
+	if err != nil {
+		return LogLevel{}, err
+	}
+	resp := result.(LogLevel)
+	return resp, nil
+}
+
+type setLogLevelRequest struct {
+	Level string `json:"loglevel"`
+}
+
+func (s setLogLevelRequest) params() (apiParams, error) {
+	if !stringInSlice(s.Level, LogLevels) {
+		return apiParams{}, fmt.Errorf("unknown log level: %s", s.Level)
+	}
+
+	p, err := commandParams("set_loglevel", 1, s)
+	p.admin = true
+	return p, err
+}
+
+func (s setLogLevelRequest) parseResponse(body []byte) (Response, error) {
+	return parseAction("set_loglevel", body)
+}
+
+// SetLogLevel changes the log level of the ejabberd node. level is one
+// of LogLevels. It requires admin rights.
+func (c Client) SetLogLevel(level string) error {
+	return c.SetLogLevelContext(context.Background(), level)
+}
+
+// SetLogLevelContext is like SetLogLevel but carries ctx into the API
+// call.
+func (c Client) SetLogLevelContext(ctx context.Context, level string) error {
+	_, err := c.call(ctx, setLogLevelRequest{Level: level})
+	return err
+}
+
+//==============================================================================
+
+type stopKindlyRequest struct {
+	Delay        time.Duration `json:"delay"`
+	Announcement string        `json:"announcement"`
+}
+
+func (s stopKindlyRequest) params() (apiParams, error) {
+	if s.Delay < 0 {
+		return apiParams{}, fmt.Errorf("delay must not be negative: %s", s.Delay)
+	}
+
+	type stopKindly struct {
+		Delay        int    `json:"delay"`
+		Announcement string `json:"announcement"`
+	}
+
+	p, err := commandParams("stop_kindly", 1, stopKindly{
+		Delay:        int(s.Delay / time.Second),
+		Announcement: s.Announcement,
+	})
+	p.admin = true
+	return p, err
+}
+
+func (s stopKindlyRequest) parseResponse(body []byte) (Response, error) {
+	return parseAction("stop_kindly", body)
+}
+
+// StopKindly broadcasts announcement to connected users, waits for
+// delay, rounded down to the second, then cleanly stops the ejabberd
+// node. The call returns when the node stops, so ctx deadline and
+// Client.HTTPClient timeout, if any, must be longer than delay. It
+// requires admin rights.
+func (c Client) StopKindly(delay time.Duration, announcement string) error {
+	return c.StopKindlyContext(context.Background(), delay, announcement)
+}
+
+// StopKindlyContext is like StopKindly but carries ctx into the API
+// call.
+func (c Client) StopKindlyContext(ctx context.Context, delay time.Duration, announcement string) error {
+	_, err := c.call(ctx, stopKindlyRequest{Delay: delay, Announcement: announcement})
+	return err
+}
@@ -0,0 +1,51 @@
+package ejabberd_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/processone/ejabberd-api"
+)
+
+func Test_Status(t *testing.T) {
+	server, calls := newAPIServer(t, func(call apiCall) (int, string) {
+		return 200, `"The node ejabberd@localhost is started with status: started\nejabberd 23.10 is running in that node"`
+	})
+	defer server.Close()
+
+	client := ejabberd.Client{BaseURL: server.URL}
+
+	status, err := client.Status()
+	if err != nil {
+		t.Fatalf("Status failed: %s", err)
+	}
+	if status.Node != "ejabberd@localhost" || status.Status != "started" || !status.Running || status.Version != "23.10" {
+		t.Errorf("Incorrect status %s", status.JSON())
+	}
+
+	checkCalls(t, *calls, []apiCall{{command: "status", admin: true, args: map[string]interface{}{}}})
+}
+
+func Test_StopKindly(t *testing.T) {
+	server, calls := newAPIServer(t, func(call apiCall) (int, string) {
+		return 200, `0`
+	})
+	defer server.Close()
+
+	client := ejabberd.Client{BaseURL: server.URL}
+
+	if err := client.SetLogLevel("verbose"); err == nil {
+		t.Errorf("SetLogLevel accepted an unknown level")
+	}
+	if err := client.SetLogLevel("debug"); err != nil {
+		t.Fatalf("SetLogLevel failed: %s", err)
+	}
+	if err := client.StopKindly(90*time.Second, "Upgrade in progress"); err != nil {
+		t.Fatalf("StopKindly failed: %s", err)
+	}
+
+	checkCalls(t, *calls, []apiCall{
+		{command: "set_loglevel", admin: true, args: map[string]interface{}{"loglevel": "debug"}},
+		{command: "stop_kindly", admin: true, args: map[string]interface{}{"delay": float64(90), "announcement": "Upgrade in progress"}},
+	})
+}
@@ -0,0 +1,401 @@
+package ejabberd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Session management commands, from ejabberd_sm and mod_admin_extra.
+
+//==============================================================================
+
+// Session describes a connected user session, as returned by ejabberd
+// user_sessions_info and connected_users_info API.
+type Session struct {
+	JID        string `json:"jid"`
+	Resource   string `json:"resource"`
+	Connection string `json:"connection"` // c2s, c2s_tls, http_bind, websocket...
+	IP         string `json:"ip"`
+	Port       int    `json:"port"`
+	Priority   int    `json:"priority"`
+	Node       string `json:"node"`
+	Uptime     int    `json:"uptime"` // in seconds
+	Status     string `json:"status"`
+	StatusText string `json:"statustext"`
+}
+
+func (s Session) String() string {
+	return fmt.Sprintf("%s\t%s\t%s:%d\t%s\t%ds\t%s", s.JID, s.Connection, s.IP, s.Port, s.Node, s.Uptime, s.Status)
+}
+
+// Sessions is a list of connected user sessions.
+type Sessions []Session
+
+// JSON represents Sessions as a JSON array, for further processing
+// with other tools.
+func (s Sessions) JSON() string {
+	body, _ := json.Marshal(s)
+	return string(body)
+}
+
+// String represents Sessions with one session per line.
+func (s Sessions) String() string {
+	lines := make([]string, len(s))
+	for i, session := range s {
+		lines[i] = session.String()
+	}
+	return strings.Join(lines, "\n")
+}
+
+// ConnectedUsers contains the full JIDs of connected sessions, as
+// returned by ejabberd connected_users and connected_users_vhost API.
+type ConnectedUsers []string
+
+// JSON represents ConnectedUsers as a JSON array, for further
+// processing with other tools.
+func (c ConnectedUsers) JSON() string {
+	body, _ := json.Marshal(c)
+	return string(body)
+}
+
+// String represents ConnectedUsers with one JID per line.
+func (c ConnectedUsers) String() string {
+	return strings.Join(c, "\n")
+}
+
+// ResourceCount contains a number of resources of a user, as returned
+// by ejabberd num_resources and kick_user API.
+type ResourceCount struct {
+	Name  string `json:"name"`
+	JID   string `json:"jid"`
+	Value int    `json:"value"`
+}
+
+// JSON represents ResourceCount as a JSON string, for further
+// processing with other tools.
+func (r ResourceCount) JSON() string {
+	body, _ := json.Marshal(r)
+	return string(body)
+}
+
+func (r ResourceCount) String() string {
+	return fmt.Sprintf("%d", r.Value)
+}
+
+//==============================================================================
+
+type userSessionsInfoRequest struct {
+	JID string `json:"jid"`
+}
+
+func (u userSessionsInfoRequest) params() (apiParams, error) {
+	jid, err := parseJID(u.JID)
+	if err != nil {
+		return apiParams{}, err
+	}
+
+	type userSessionsInfo struct {
+		User string `json:"user"`
+		Host string `json:"host"`
+	}
+
+	p, err := commandParams("user_sessions_info", 1, userSessionsInfo{
+		User: jid.username,
+		Host: jid.domain,
+	})
+	p.idempotent = true
+	return p, err
+}
+
+func (u userSessionsInfoRequest) parseResponse(body []byte) (Response, error) {
+	var resp Sessions
+	if err := decodeResponse("user_sessions_info", body, &resp); err != nil {
+		return Sessions{}, err
+	}
+	for i := range resp {
+		resp[i].JID = u.JID + "/" + resp[i].Resource
+	}
+	return resp, nil
+}
+
+// UserSessions returns the sessions of a user. It can be called as a
+// user, to read your own sessions, or as an admin to read the
+// sessions of any user.
+func (c Client) UserSessions(bareJID string) (Sessions, error) {
+	return c.UserSessionsContext(context.Background(), bareJID)
+}
+
+// UserSessionsContext is like UserSessions but carries ctx into the
+// API call.
+func (c Client) UserSessionsContext(ctx context.Context, bareJID string) (Sessions, error) {
+	result, err := c.call(ctx, userSessionsInfoRequest{JID: bareJID})
+	if err != nil {
+		return Sessions{}, err
+	}
+	resp := result.(Sessions)
+	return resp, nil
+}
+
+//==============================================================================
+
+type connectedUsersRequest struct{}
+
+func (r connectedUsersRequest) params() (apiParams, error) {
+	p, err := commandParams("connected_users", 1, r)
+	p.admin = true
+	p.idempotent = true
+	return p, err
+}
+
+func (r connectedUsersRequest) parseResponse(body []byte) (Response, error) {
+	var resp ConnectedUsers
+	err := decodeResponse("connected_users", body, &resp)
+	return resp, err
+}
+
+// ConnectedUsers returns the full JIDs of all sessions connected to
+// the server. It requires admin rights.
+func (c Client) ConnectedUsers() (ConnectedUsers, error) {
+	return c.ConnectedUsersContext(context.Background())
+}
+
+// ConnectedUsersContext is like ConnectedUsers but carries ctx into
+// the API call.
+func (c Client) ConnectedUsersContext(ctx context.Context) (ConnectedUsers, error) {
+	result, err := c.call(ctx, connectedUsersRequest{})
+	if err != nil {
+		return ConnectedUsers{}, err
+	}
+	resp := result.(ConnectedUsers)
+	return resp, nil
+}
+
+//==============================================================================
+
+type connectedUsersVHostRequest struct {
+	Host string `json:"host"`
+}
+
+func (r connectedUsersVHostRequest) params() (apiParams, error) {
+	if r.Host == "" {
+		return apiParams{}, fmt.Errorf("required argument 'host' not provided")
+	}
+
+	p, err := commandParams("connected_users_vhost", 1, r)
+	p.admin = true
+	p.idempotent = true
+	return p, err
+}
+
+func (r connectedUsersVHostRequest) parseResponse(body []byte) (Response, error) {
+	var resp ConnectedUsers
+	err := decodeResponse("connected_users_vhost", body, &resp)
+	return resp, err
+}
+
+// ConnectedUsersVHost returns the full JIDs of the sessions connected
+// on a virtual host. It requires admin rights.
+func (c Client) ConnectedUsersVHost(host string) (ConnectedUsers, error) {
+	return c.ConnectedUsersVHostContext(context.Background(), host)
+}
+
+// ConnectedUsersVHostContext is like ConnectedUsersVHost but carries
+// ctx into the API call.
+func (c Client) ConnectedUsersVHostContext(ctx context.Context, host string) (ConnectedUsers, error) {
+	result, err := c.call(ctx, connectedUsersVHostRequest{Host: host})
+	if err != nil {
+		return ConnectedUsers{}, err
+	}
+	resp := result.(ConnectedUsers)
+	return resp, nil
+}
+
+//==============================================================================
+
+type connectedUsersInfoRequest struct{}
+
+func (r connectedUsersInfoRequest) params() (apiParams, error) {
+	p, err := commandParams("connected_users_info", 1, r)
+	p.admin = true
+	p.idempotent = true
+	return p, err
+}
+
+func (r connectedUsersInfoRequest) parseResponse(body []byte) (Response, error) {
+	var resp Sessions
+	if err := decodeResponse("connected_users_info", body, &resp); err != nil {
+		return Sessions{}, err
+	}
+	for i := range resp {
+		if j, err := parseJID(resp[i].JID); err == nil && resp[i].Resource == "" {
+			resp[i].Resource = j.resource
+		}
+	}
+	return resp, nil
+}
+
+// ConnectedUsersInfo returns all sessions connected to the server,
+// with their details. It requires admin rights.
+func (c Client) ConnectedUsersInfo() (Sessions, error) {
+	return c.ConnectedUsersInfoContext(context.Background())
+}
+
+// ConnectedUsersInfoContext is like ConnectedUsersInfo but carries ctx
+// into the API call.
+func (c Client) ConnectedUsersInfoContext(ctx context.Context) (Sessions, error) {
+	result, err := c.call(ctx, connectedUsersInfoRequest{})
+	if err != nil {
+		return Sessions{}, err
+	}
+	resp := result.(Sessions)
+	return resp, nil
+}
+
+//==============================================================================
+
+type kickSessionRequest struct {
+	JID    string `json:"jid"`
+	Reason string `json:"reason"`
+}
+
+func (k kickSessionRequest) params() (apiParams, error) {
+	jid, err := parseJID(k.JID)
+	if err != nil {
+		return apiParams{}, err
+	}
+	if jid.resource == "" {
+		return apiParams{}, fmt.Errorf("full jid with resource required: %s", k.JID)
+	}
+
+	type kickSession struct {
+		User     string `json:"user"`
+		Host     string `json:"host"`
+		Resource string `json:"resource"`
+		Reason   string `json:"reason"`
+	}
+
+	p, err := commandParams("kick_session", 1, kickSession{
+		User:     jid.username,
+		Host:     jid.domain,
+		Resource: jid.resource,
+		Reason:   k.Reason,
+	})
+	p.admin = true
+	return p, err
+}
+
+func (k kickSessionRequest) parseResponse(body []byte) (Response, error) {
+	return parseAction("kick_session", body)
+}
+
+// KickSession disconnects the session of a full JID
+// (user@domain/resource), sending reason to the client. It requires
+// admin rights.
+func (c Client) KickSession(fullJID, reason string) error {
+	return c.KickSessionContext(context.Background(), fullJID, reason)
+}
+
+// KickSessionContext is like KickSession but carries ctx into the API
+// call.
+func (c Client) KickSessionContext(ctx context.Context, fullJID, reason string) error {
+	_, err := c.call(ctx, kickSessionRequest{JID: fullJID, Reason: reason})
+	return err
+}
+
+//==============================================================================
+
+type kickUserRequest struct {
+	JID string `json:"jid"`
+}
+
+func (k kickUserRequest) params() (apiParams, error) {
+	jid, err := parseJID(k.JID)
+	if err != nil {
+		return apiParams{}, err
+	}
+
+	type kickUser struct {
+		User string `json:"user"`
+		Host string `json:"host"`
+	}
+
+	p, err := commandParams("kick_user", 1, kickUser{
+		User: jid.username,
+		Host: jid.domain,
+	})
+	p.admin = true
+	return p, err
+}
+
+func (k kickUserRequest) parseResponse(body []byte) (Response, error) {
+	resp := ResourceCount{Name: "kick_user", JID: k.JID}
+	err := decodeResponse("kick_user", body, &resp.Value)
+	return resp, err
+}
+
+// KickUser disconnects all sessions of a user and returns the number
+// of closed sessions. It requires admin rights.
+func (c Client) KickUser(bareJID string) (ResourceCount, error) {
+	return c.KickUserContext(context.Background(), bareJID)
+}
+
+// KickUserContext is like KickUser but carries ctx into the API call.
+func (c Client) KickUserContext(ctx context.Context, bareJID string) (ResourceCount, error) {
+	result, err := c.call(ctx, kickUserRequest{JID: bareJID})
+	if err != nil {
+		return ResourceCount{}, err
+	}
+	resp := result.(ResourceCount)
+	return resp, nil
+}
+
+//==============================================================================
+
+type numResourcesRequest struct {
+	JID string `json:"jid"`
+}
+
+func (n numResourcesRequest) params() (apiParams, error) {
+	jid, err := parseJID(n.JID)
+	if err != nil {
+		return apiParams{}, err
+	}
+
+	type numResources struct {
+		User string `json:"user"`
+		Host string `json:"host"`
+	}
+
+	p, err := commandParams("num_resources", 1, numResources{
+		User: jid.username,
+		Host: jid.domain,
+	})
+	p.idempotent = true
+	return p, err
+}
+
+func (n numResourcesRequest) parseResponse(body []byte) (Response, error) {
+	resp := ResourceCount{Name: "num_resources", JID: n.JID}
+	err := decodeResponse("num_resources", body, &resp.Value)
+	return resp, err
+}
+
+// NumResources returns the number of resources connected for a user.
+// It can be called as a user for your own account, or as an admin
+// for any user.
+func (c Client) NumResources(bareJID string) (ResourceCount, error) {
+	return c.NumResourcesContext(context.Background(), bareJID)
+}
+
+// NumResourcesContext is like NumResources but carries ctx into the
+// API call.
+func (c Client) NumResourcesContext(ctx context.Context, bareJID string) (ResourceCount, error) {
+	result, err := c.call(ctx, numResourcesRequest{JID: bareJID})
+	if err != nil {
+		return ResourceCount{}, err
+	}
+	resp := result.(ResourceCount)
+	return resp, nil
+}
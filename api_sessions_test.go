@@ -0,0 +1,53 @@
+package ejabberd_test
+
+import (
+	"testing"
+
+	"github.com/processone/ejabberd-api"
+)
+
+func Test_Sessions(t *testing.T) {
+	server, calls := newAPIServer(t, func(call apiCall) (int, string) {
+		switch call.command {
+		case "user_sessions_info":
+			return 200, `[{"connection":"c2s_tls","ip":"192.0.2.1","port":54321,"priority":5,
+			               "node":"ejabberd@node1","uptime":3600,"status":"available",
+			               "resource":"phone","statustext":""}]`
+		case "kick_user":
+			return 200, `2`
+		}
+		return 200, `0`
+	})
+	defer server.Close()
+
+	client := ejabberd.Client{BaseURL: server.URL, Token: ejabberd.OAuthToken{JID: "alice@localhost"}}
+
+	sessions, err := client.UserSessions("alice@localhost")
+	if err != nil {
+		t.Fatalf("UserSessions failed: %s", err)
+	}
+	want := ejabberd.Session{
+		JID: "alice@localhost/phone", Resource: "phone", Connection: "c2s_tls",
+		IP: "192.0.2.1", Port: 54321, Priority: 5, Node: "ejabberd@node1",
+		Uptime: 3600, Status: "available",
+	}
+	if len(sessions) != 1 || sessions[0] != want {
+		t.Errorf("Incorrect sessions %+v", sessions)
+	}
+
+	if err := client.KickSession("alice@localhost", "compromised"); err == nil {
+		t.Errorf("KickSession should require a full JID")
+	}
+	if err := client.KickSession("alice@localhost/phone", "compromised"); err != nil {
+		t.Errorf("KickSession failed: %s", err)
+	}
+	if count, err := client.KickUser("alice@localhost"); err != nil || count.Value != 2 {
+		t.Errorf("KickUser = %v, %v; want 2", count, err)
+	}
+
+	checkCalls(t, *calls, []apiCall{
+		{"user_sessions_info", false, map[string]interface{}{"user": "alice", "host": "localhost"}},
+		{"kick_session", true, map[string]interface{}{"user": "alice", "host": "localhost", "resource": "phone", "reason": "compromised"}},
+		{"kick_user", true, map[string]interface{}{"user": "alice", "host": "localhost"}},
+	})
+}
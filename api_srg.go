@@ -0,0 +1,457 @@
+package ejabberd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Shared roster group commands, from mod_shared_roster.
+
+//==============================================================================
+
+// SRGInfo describes a shared roster group of a virtual host.
+type SRGInfo struct {
+	Name            string   `json:"name"` // Group identifier
+	Host            string   `json:"host"`
+	Label           string   `json:"label"`
+	Description     string   `json:"description"`
+	DisplayedGroups []string `json:"displayed_groups"`
+
+	// AllUsers and OnlineUsers report if the group contains all users
+	// of the virtual host, or all its online users. They are ignored
+	// by CreateSRG.
+	AllUsers    bool `json:"all_users"`
+	OnlineUsers bool `json:"online_users"`
+}
+
+// JSON represents SRGInfo as a JSON string, for further processing
+// with other tools.
+func (s SRGInfo) JSON() string {
+	body, _ := json.Marshal(s)
+	return string(body)
+}
+
+func (s SRGInfo) String() string {
+	return fmt.Sprintf("%s\t%s\t%s\t%s", s.Name, s.Label, s.Description, strings.Join(s.DisplayedGroups, ","))
+}
+
+// SRGroups contains shared roster group identifiers, as returned by
+// ejabberd srg_list API.
+type SRGroups []string
+
+// JSON represents SRGroups as a JSON array, for further processing
+// with other tools.
+func (s SRGroups) JSON() string {
+	body, _ := json.Marshal(s)
+	return string(body)
+}
+
+// String represents SRGroups with one group per line.
+func (s SRGroups) String() string {
+	return strings.Join(s, "\n")
+}
+
+// SRGMembers contains the bare JIDs of the members of a shared roster
+// group, as returned by ejabberd srg_get_members API.
+type SRGMembers []string
+
+// JSON represents SRGMembers as a JSON array, for further processing
+// with other tools.
+func (s SRGMembers) JSON() string {
+	body, _ := json.Marshal(s)
+	return string(body)
+}
+
+// String represents SRGMembers with one JID per line.
+func (s SRGMembers) String() string {
+	return strings.Join(s, "\n")
+}
+
+//==============================================================================
+
+// srgArgs are the arguments identifying a shared roster group.
+type srgArgs struct {
+	Group string `json:"group"`
+	Host  string `json:"host"`
+}
+
+func newSRGArgs(group, host string) (srgArgs, error) {
+	if group == "" {
+		return srgArgs{}, fmt.Errorf("required argument 'group' not provided")
+	}
+	if host == "" {
+		return srgArgs{}, fmt.Errorf("required argument 'host' not provided")
+	}
+	return srgArgs{Group: group, Host: host}, nil
+}
+
+//==============================================================================
+
+type srgCreateRequest struct {
+	Info SRGInfo `json:"info"`
+}
+
+func (s srgCreateRequest) params() (apiParams, error) {
+	group, err := newSRGArgs(s.Info.Name, s.Info.Host)
+	if err != nil {
+		return apiParams{}, err
+	}
+
+	type srgCreate struct {
+		srgArgs
+		Label       string   `json:"label"`
+		Description string   `json:"description"`
+		Display     []string `json:"display"`
+	}
+
+	label := s.Info.Label
+	if label == "" {
+		label = s.Info.Name
+	}
+	display := s.Info.DisplayedGroups
+	if display == nil {
+		display = []string{}
+	}
+
+	p, err := commandParams("srg_create", 1, srgCreate{
+		srgArgs:     group,
+		Label:       label,
+		Description: s.Info.Description,
+		Display:     display,
+	})
+	p.admin = true
+	return p, err
+}
+
+func (s srgCreateRequest) parseResponse(body []byte) (Response, error) {
+	return parseAction("srg_create", body)
+}
+
+// CreateSRG creates a shared roster group, identified by info.Name on
+// virtual host info.Host. Label defaults to the group name. It
+// requires admin rights.
+func (c Client) CreateSRG(info SRGInfo) error {
+	return c.CreateSRGContext(context.Background(), info)
+}
+
+// CreateSRGContext is like CreateSRG but carries ctx into the API
+// call.
+func (c Client) CreateSRGContext(ctx context.Context, info SRGInfo) error {
+	_, err := c.call(ctx, srgCreateRequest{Info: info})
+	return err
+}
+
+//==============================================================================
+
+type srgDeleteRequest struct {
+	Group string `json:"group"`
+	Host  string `json:"host"`
+}
+
+func (s srgDeleteRequest) params() (apiParams, error) {
+	group, err := newSRGArgs(s.Group, s.Host)
+	if err != nil {
+		return apiParams{}, err
+	}
+
+	p, err := commandParams("srg_delete", 1, group)
+	p.admin = true
+	return p, err
+}
+
+func (s srgDeleteRequest) parseResponse(body []byte) (Response, error) {
+	return parseAction("srg_delete", body)
+}
+
+// DeleteSRG deletes a shared roster group. It requires admin rights.
+func (c Client) DeleteSRG(group, host string) error {
+	return c.DeleteSRGContext(context.Background(), group, host)
+}
+
+// DeleteSRGContext is like DeleteSRG but carries ctx into the API
+// call.
+func (c Client) DeleteSRGContext(ctx context.Context, group, host string) error {
+	_, err := c.call(ctx, srgDeleteRequest{Group: group, Host: host})
+	return err
+}
+
+//==============================================================================
+
+type srgListRequest struct {
+	Host string `json:"host"`
+}
+
+func (s srgListRequest) params() (apiParams, error) {
+	if s.Host == "" {
+		return apiParams{}, fmt.Errorf("required argument 'host' not provided")
+	}
+
+	p, err := commandParams("srg_list", 1, s)
+	p.admin = true
+	p.idempotent = true
+	return p, err
+}
+
+func (s srgListRequest) parseResponse(body []byte) (Response, error) {
+	var resp SRGroups
+	err := decodeResponse("srg_list", body, &resp)
+	return resp, err
+}
+
+// ListSRG returns the identifiers of the shared roster groups of a
+// virtual host. It requires admin rights.
+func (c Client) ListSRG(host string) (SRGroups, error) {
+	return c.ListSRGContext(context.Background(), host)
+}
+
+// ListSRGContext is like ListSRG but carries ctx into the API call.
+func (c Client) ListSRGContext(ctx context.Context, host string) (SRGroups, error) {
+	result, err := c.call(ctx, srgListRequest{Host: host})
+	if err != nil {
+		return SRGroups{}, err
+	}
+	resp := result.(SRGroups)
+	return resp, nil
+}
+
+//==============================================================================
+
+type srgGetInfoRequest struct {
+	Group string `json:"group"`
+	Host  string `json:"host"`
+}
+
+func (s srgGetInfoRequest) params() (apiParams, error) {
+	group, err := newSRGArgs(s.Group, s.Host)
+	if err != nil {
+		return apiParams{}, err
+	}
+
+	p, err := commandParams("srg_get_info", 1, group)
+	p.admin = true
+	p.idempotent = true
+	return p, err
+}
+
+func (s srgGetInfoRequest) parseResponse(body []byte) (Response, error) {
+	// Info is returned as a list of key / value pairs, with values
+	// formatted as Erlang terms.
+	var data []struct {
+		Key   string `json:"key"`
+		Value string `json:"value"`
+	}
+	if err := decodeResponse("srg_get_info", body, &data); err != nil {
+		return SRGInfo{}, err
+	}
+
+	resp := SRGInfo{Name: s.Group, Host: s.Host}
+	for _, info := range data {
+		value := unquoteTerm(info.Value)
+		switch info.Key {
+		case "label", "name":
+			resp.Label = value
+		case "description":
+			resp.Description = value
+		case "displayed_groups":
+			resp.DisplayedGroups = parseTermList(value)
+		case "all_users":
+			resp.AllUsers = value == "true"
+		case "online_users":
+			resp.OnlineUsers = value == "true"
+		}
+	}
+	return resp, nil
+}
+
+// unquoteTerm removes the quotes around an Erlang string or binary
+// term, like "text" or <<"text">>.
+func unquoteTerm(s string) string {
+	s = strings.TrimSpace(s)
+	if strings.HasPrefix(s, "<<") && strings.HasSuffix(s, ">>") {
+		s = s[2 : len(s)-2]
+	}
+	if len(s) >= 2 && s[0] == '"' && s[len(s)-1] == '"' {
+		s = s[1 : len(s)-1]
+	}
+	return s
+}
+
+// parseTermList parses an Erlang list of strings, binaries or atoms,
+// like [<<"a">>,"b",c]. Commas and escaped quotes inside quoted
+// elements are kept.
+func parseTermList(s string) []string {
+	s = strings.TrimSpace(s)
+	s = strings.TrimSuffix(strings.TrimPrefix(s, "["), "]")
+	list := []string{}
+	for {
+		s = strings.TrimLeft(s, " \t\n,")
+		if s == "" {
+			return list
+		}
+		var elt string
+		elt, s = nextTerm(s)
+		if elt != "" {
+			list = append(list, elt)
+		}
+	}
+}
+
+// nextTerm reads the string, binary or atom term at the start of s and
+// returns its unquoted value and the rest of s.
+func nextTerm(s string) (string, string) {
+	binary := strings.HasPrefix(s, "<<")
+	if binary {
+		s = s[2:]
+	}
+	if !strings.HasPrefix(s, `"`) {
+		i := strings.IndexByte(s, ',')
+		if i < 0 {
+			i = len(s)
+		}
+		return strings.TrimSpace(strings.TrimSuffix(s[:i], ">>")), s[i:]
+	}
+
+	var b strings.Builder
+	i := 1
+	for ; i < len(s) && s[i] != '"'; i++ {
+		if s[i] == '\\' && i+1 < len(s) {
+			i++
+		}
+		b.WriteByte(s[i])
+	}
+	s = s[min(i+1, len(s)):]
+	if binary {
+		// Skip type specifiers, like /utf8, up to the end of binary.
+		if j := strings.Index(s, ">>"); j >= 0 {
+			s = s[j+2:]
+		}
+	}
+	return b.String(), s
+}
+
+// GetSRGInfo returns the description of a shared roster group. It
+// requires admin rights.
+func (c Client) GetSRGInfo(group, host string) (SRGInfo, error) {
+	return c.GetSRGInfoContext(context.Background(), group, host)
+}
+
+// GetSRGInfoContext is like GetSRGInfo but carries ctx into the API
+// call.
+func (c Client) GetSRGInfoContext(ctx context.Context, group, host string) (SRGInfo, error) {
+	result, err := c.call(ctx, srgGetInfoRequest{Group: group, Host: host})
+	if err != nil {
+		return SRGInfo{}, err
+	}
+	resp := result.(SRGInfo)
+	return resp, nil
+}
+
+//==============================================================================
+
+type srgGetMembersRequest struct {
+	Group string `json:"group"`
+	Host  string `json:"host"`
+}
+
+func (s srgGetMembersRequest) params() (apiParams, error) {
+	group, err := newSRGArgs(s.Group, s.Host)
+	if err != nil {
+		return apiParams{}, err
+	}
+
+	p, err := commandParams("srg_get_members", 1, group)
+	p.admin = true
+	p.idempotent = true
+	return p, err
+}
+
+func (s srgGetMembersRequest) parseResponse(body []byte) (Response, error) {
+	var resp SRGMembers
+	err := decodeResponse("srg_get_members", body, &resp)
+	return resp, err
+}
+
+// GetSRGMembers returns the bare JIDs of the members of a shared
+// roster group. It requires admin rights.
+func (c Client) GetSRGMembers(group, host string) (SRGMembers, error) {
+	return c.GetSRGMembersContext(context.Background(), group, host)
+}
+
+// GetSRGMembersContext is like GetSRGMembers but carries ctx into the
+// API call.
+func (c Client) GetSRGMembersContext(ctx context.Context, group, host string) (SRGMembers, error) {
+	result, err := c.call(ctx, srgGetMembersRequest{Group: group, Host: host})
+	if err != nil {
+		return SRGMembers{}, err
+	}
+	resp := result.(SRGMembers)
+	return resp, nil
+}
+
+//==============================================================================
+
+type srgUserRequest struct {
+	Name  string `json:"-"`
+	User  string `json:"user"`
+	Group string `json:"group"`
+	Host  string `json:"host"`
+}
+
+func (s srgUserRequest) params() (apiParams, error) {
+	jid, err := parseJID(s.User)
+	if err != nil {
+		return apiParams{}, err
+	}
+	group, err := newSRGArgs(s.Group, s.Host)
+	if err != nil {
+		return apiParams{}, err
+	}
+
+	type srgUser struct {
+		User      string `json:"user"`
+		Host      string `json:"host"`
+		Group     string `json:"group"`
+		GroupHost string `json:"grouphost"`
+	}
+
+	p, err := commandParams(s.Name, 1, srgUser{
+		User:      jid.username,
+		Host:      jid.domain,
+		Group:     group.Group,
+		GroupHost: group.Host,
+	})
+	p.admin = true
+	return p, err
+}
+
+func (s srgUserRequest) parseResponse(body []byte) (Response, error) {
+	return parseAction(s.Name, body)
+}
+
+// AddSRGUser adds a user to a shared roster group of a virtual host.
+// It requires admin rights.
+func (c Client) AddSRGUser(bareJID, group, host string) error {
+	return c.AddSRGUserContext(context.Background(), bareJID, group, host)
+}
+
+// AddSRGUserContext is like AddSRGUser but carries ctx into the API
+// call.
+func (c Client) AddSRGUserContext(ctx context.Context, bareJID, group, host string) error {
+	_, err := c.call(ctx, srgUserRequest{Name: "srg_user_add", User: bareJID, Group: group, Host: host})
+	return err
+}
+
+// DeleteSRGUser removes a user from a shared roster group of a
+// virtual host. It requires admin rights.
+func (c Client) DeleteSRGUser(bareJID, group, host string) error {
+	return c.DeleteSRGUserContext(context.Background(), bareJID, group, host)
+}
+
+// DeleteSRGUserContext is like DeleteSRGUser but carries ctx into the
+// API call.
+func (c Client) DeleteSRGUserContext(ctx context.Context, bareJID, group, host string) error {
+	_, err := c.call(ctx, srgUserRequest{Name: "srg_user_del", User: bareJID, Group: group, Host: host})
+	return err
+}
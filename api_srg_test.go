@@ -0,0 +1,65 @@
+package ejabberd_test
+
+import (
+	"testing"
+
+	"github.com/processone/ejabberd-api"
+)
+
+func Test_CreateSRG(t *testing.T) {
+	server, calls := newAPIServer(t, func(call apiCall) (int, string) {
+		return 200, `0`
+	})
+	defer server.Close()
+
+	client := ejabberd.Client{BaseURL: server.URL}
+
+	if err := client.CreateSRG(ejabberd.SRGInfo{Name: "sales"}); err == nil {
+		t.Errorf("CreateSRG accepted a group without host")
+	}
+	err := client.CreateSRG(ejabberd.SRGInfo{Name: "sales", Host: "localhost", DisplayedGroups: []string{"sales", "support"}})
+	if err != nil {
+		t.Fatalf("CreateSRG failed: %s", err)
+	}
+	if err := client.AddSRGUser("alice@localhost", "sales", "localhost"); err != nil {
+		t.Fatalf("AddSRGUser failed: %s", err)
+	}
+
+	checkCalls(t, *calls, []apiCall{
+		{command: "srg_create", admin: true, args: map[string]interface{}{
+			"group": "sales", "host": "localhost", "label": "sales", "description": "",
+			"display": []interface{}{"sales", "support"},
+		}},
+		{command: "srg_user_add", admin: true, args: map[string]interface{}{
+			"user": "alice", "host": "localhost", "group": "sales", "grouphost": "localhost",
+		}},
+	})
+}
+
+func Test_GetSRGInfo(t *testing.T) {
+	server, _ := newAPIServer(t, func(call apiCall) (int, string) {
+		return 200, `[{"key":"label","value":"\"Sales team\""},
+		              {"key":"description","value":"<<\"EMEA sales\">>"},
+		              {"key":"all_users","value":"true"},
+		              {"key":"displayed_groups","value":"[<<\"sales\">>,<<\"support, \\\"tier 2\\\"\"/utf8>>,\"ops\"]"}]`
+	})
+	defer server.Close()
+
+	client := ejabberd.Client{BaseURL: server.URL}
+
+	info, err := client.GetSRGInfo("sales", "localhost")
+	if err != nil {
+		t.Fatalf("GetSRGInfo failed: %s", err)
+	}
+	want := ejabberd.SRGInfo{
+		Name:            "sales",
+		Host:            "localhost",
+		Label:           "Sales team",
+		Description:     "EMEA sales",
+		DisplayedGroups: []string{"sales", `support, "tier 2"`, "ops"},
+		AllUsers:        true,
+	}
+	if info.JSON() != want.JSON() {
+		t.Errorf("GetSRGInfo = %s; want %s", info.JSON(), want.JSON())
+	}
+}
@@ -0,0 +1,168 @@
+package ejabberd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Listing of accounts and virtual hosts, from ejabberd_admin.
+
+//==============================================================================
+
+// RegisteredUsers contains the bare JIDs of the users registered on a
+// virtual host, as returned by ejabberd registered_users API.
+type RegisteredUsers []string
+
+// JSON represents RegisteredUsers as a JSON array, for further
+// processing with other tools.
+func (r RegisteredUsers) JSON() string {
+	body, _ := json.Marshal(r)
+	return string(body)
+}
+
+// String represents RegisteredUsers with one JID per line.
+func (r RegisteredUsers) String() string {
+	return strings.Join(r, "\n")
+}
+
+type registeredUsersRequest struct {
+	Host string `json:"host"`
+}
+
+func (r registeredUsersRequest) params() (apiParams, error) {
+	if r.Host == "" {
+		return apiParams{}, fmt.Errorf("required argument 'host' not provided")
+	}
+
+	p, err := commandParams("registered_users", 1, r)
+	p.admin = true
+	p.idempotent = true
+	return p, err
+}
+
+func (r registeredUsersRequest) parseResponse(body []byte) (Response, error) {
+	var users []string
+	if err := decodeResponse("registered_users", body, &users); err != nil {
+		return RegisteredUsers{}, err
+	}
+
+	resp := make(RegisteredUsers, len(users))
+	for i, user := range users {
+		resp[i] = r.jid(user)
+	}
+	return resp, nil
+}
+
+// jid turns a user name returned by ejabberd into a bare JID.
+func (r registeredUsersRequest) jid(user string) string {
+	return jid{username: user, domain: r.Host}.bare()
+}
+
+// RegisteredUsers returns the bare JIDs of all users registered on a
+// virtual host. It requires admin rights.
+//
+// The whole list is loaded in memory and is subject to
+// Client.MaxResponseSize. Use EachRegisteredUser on large virtual
+// hosts.
+func (c Client) RegisteredUsers(host string) (RegisteredUsers, error) {
+	return c.RegisteredUsersContext(context.Background(), host)
+}
+
+// RegisteredUsersContext is like RegisteredUsers but carries ctx into
+// the API call.
+func (c Client) RegisteredUsersContext(ctx context.Context, host string) (RegisteredUsers, error) {
+	result, err := c.call(ctx, registeredUsersRequest{Host: host})
+	if err != nil {
+		return RegisteredUsers{}, err
+	}
+	resp := result.(RegisteredUsers)
+	return resp, nil
+}
+
+// EachRegisteredUser calls fn with the bare JID of each user
+// registered on a virtual host. The list is streamed from the server
+// and is not subject to Client.MaxResponseSize. It stops on the first
+// error returned by fn.
+func (c Client) EachRegisteredUser(host string, fn func(jid string) error) error {
+	return c.EachRegisteredUserContext(context.Background(), host, fn)
+}
+
+// EachRegisteredUserContext is like EachRegisteredUser but carries ctx
+// into the API call.
+func (c Client) EachRegisteredUserContext(ctx context.Context, host string, fn func(jid string) error) error {
+	command := registeredUsersRequest{Host: host}
+	p, err := command.params()
+	if err != nil {
+		return err
+	}
+
+	body, err := c.stream(ctx, p, p.admin)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+
+	err = DecodeArray(body, func(elt json.RawMessage) error {
+		var user string
+		if err := decodeResponse(p.name, elt, &user); err != nil {
+			return err
+		}
+		return fn(command.jid(user))
+	})
+	if err != nil && ctx.Err() != nil {
+		return ctx.Err()
+	}
+	return err
+}
+
+//==============================================================================
+
+// VHosts contains the virtual hosts served by ejabberd, as returned
+// by ejabberd registered_vhosts API.
+type VHosts []string
+
+// JSON represents VHosts as a JSON array, for further processing with
+// other tools.
+func (v VHosts) JSON() string {
+	body, _ := json.Marshal(v)
+	return string(body)
+}
+
+// String represents VHosts with one host per line.
+func (v VHosts) String() string {
+	return strings.Join(v, "\n")
+}
+
+type registeredVHostsRequest struct{}
+
+func (r registeredVHostsRequest) params() (apiParams, error) {
+	p, err := commandParams("registered_vhosts", 1, r)
+	p.admin = true
+	p.idempotent = true
+	return p, err
+}
+
+func (r registeredVHostsRequest) parseResponse(body []byte) (Response, error) {
+	var resp VHosts
+	err := decodeResponse("registered_vhosts", body, &resp)
+	return resp, err
+}
+
+// RegisteredVHosts returns the list of virtual hosts served by
+// ejabberd. It requires admin rights.
+func (c Client) RegisteredVHosts() (VHosts, error) {
+	return c.RegisteredVHostsContext(context.Background())
+}
+
+// RegisteredVHostsContext is like RegisteredVHosts but carries ctx
+// into the API call.
+func (c Client) RegisteredVHostsContext(ctx context.Context) (VHosts, error) {
+	result, err := c.call(ctx, registeredVHostsRequest{})
+	if err != nil {
+		return VHosts{}, err
+	}
+	resp := result.(VHosts)
+	return resp, nil
+}
@@ -0,0 +1,64 @@
+package ejabberd_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/processone/ejabberd-api"
+)
+
+func Test_RegisteredUsers(t *testing.T) {
+	server, calls := newAPIServer(t, func(call apiCall) (int, string) {
+		if call.command == "registered_vhosts" {
+			return 200, `["localhost", "example.com"]`
+		}
+		return 200, `["alice", "bob"]`
+	})
+	defer server.Close()
+
+	client := ejabberd.Client{BaseURL: server.URL}
+
+	users, err := client.RegisteredUsers("localhost")
+	if err != nil {
+		t.Fatalf("RegisteredUsers failed: %s", err)
+	}
+	if users.String() != "alice@localhost\nbob@localhost" {
+		t.Errorf("Incorrect users %q", users)
+	}
+
+	var streamed []string
+	err = client.EachRegisteredUser("localhost", func(jid string) error {
+		streamed = append(streamed, jid)
+		return nil
+	})
+	if err != nil || len(streamed) != 2 || streamed[1] != "bob@localhost" {
+		t.Errorf("EachRegisteredUser = %v, %v", streamed, err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err = client.EachRegisteredUserContext(ctx, "localhost", func(jid string) error {
+		t.Errorf("EachRegisteredUserContext called fn after cancel with %s", jid)
+		return nil
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("EachRegisteredUserContext = %v; want %v", err, context.Canceled)
+	}
+
+	vhosts, err := client.RegisteredVHosts()
+	if err != nil || vhosts.JSON() != `["localhost","example.com"]` {
+		t.Errorf("RegisteredVHosts = %v, %v", vhosts, err)
+	}
+
+	if _, err := client.RegisteredUsers(""); err == nil {
+		t.Errorf("RegisteredUsers should fail without host")
+	}
+
+	want := []apiCall{
+		{"registered_users", true, map[string]interface{}{"host": "localhost"}},
+		{"registered_users", true, map[string]interface{}{"host": "localhost"}},
+		{"registered_vhosts", true, map[string]interface{}{}},
+	}
+	checkCalls(t, *calls, want)
+}
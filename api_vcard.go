@@ -0,0 +1,352 @@
+package ejabberd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// vCard commands, from mod_admin_extra.
+
+//==============================================================================
+
+// VCardField identifies a vCard field by its ejabberd name, like FN,
+// and subname for structured fields, like N / FAMILY.
+type VCardField struct {
+	Name    string `json:"name"`
+	Subname string `json:"subname,omitempty"`
+}
+
+func (f VCardField) String() string {
+	if f.Subname == "" {
+		return f.Name
+	}
+	return f.Name + "." + f.Subname
+}
+
+// Common vCard fields (XEP-0054).
+var (
+	VCardFullName    = VCardField{Name: "FN"}
+	VCardNickname    = VCardField{Name: "NICKNAME"}
+	VCardGivenName   = VCardField{Name: "N", Subname: "GIVEN"}
+	VCardMiddleName  = VCardField{Name: "N", Subname: "MIDDLE"}
+	VCardFamilyName  = VCardField{Name: "N", Subname: "FAMILY"}
+	VCardEmail       = VCardField{Name: "EMAIL", Subname: "USERID"}
+	VCardTelephone   = VCardField{Name: "TEL", Subname: "NUMBER"}
+	VCardURL         = VCardField{Name: "URL"}
+	VCardBirthday    = VCardField{Name: "BDAY"}
+	VCardTitle       = VCardField{Name: "TITLE"}
+	VCardRole        = VCardField{Name: "ROLE"}
+	VCardOrgName     = VCardField{Name: "ORG", Subname: "ORGNAME"}
+	VCardOrgUnit     = VCardField{Name: "ORG", Subname: "ORGUNIT"}
+	VCardLocality    = VCardField{Name: "ADR", Subname: "LOCALITY"}
+	VCardCountry     = VCardField{Name: "ADR", Subname: "CTRY"}
+	VCardDescription = VCardField{Name: "DESC"}
+	VCardPhotoType   = VCardField{Name: "PHOTO", Subname: "TYPE"}
+	VCardPhoto       = VCardField{Name: "PHOTO", Subname: "BINVAL"} // base64 encoded
+)
+
+// vcardFields maps friendly names accepted by ParseVCardField to
+// vCard fields.
+var vcardFields = map[string]VCardField{
+	"fullname":    VCardFullName,
+	"nickname":    VCardNickname,
+	"given":       VCardGivenName,
+	"middle":      VCardMiddleName,
+	"family":      VCardFamilyName,
+	"email":       VCardEmail,
+	"tel":         VCardTelephone,
+	"url":         VCardURL,
+	"birthday":    VCardBirthday,
+	"title":       VCardTitle,
+	"role":        VCardRole,
+	"org":         VCardOrgName,
+	"orgunit":     VCardOrgUnit,
+	"locality":    VCardLocality,
+	"country":     VCardCountry,
+	"description": VCardDescription,
+	"phototype":   VCardPhotoType,
+	"photo":       VCardPhoto,
+}
+
+// VCardFieldNames returns the sorted friendly names accepted by
+// ParseVCardField.
+func VCardFieldNames() []string {
+	names := make([]string, 0, len(vcardFields))
+	for name := range vcardFields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ParseVCardField returns the vCard field matching s. s is either a
+// friendly name, like fullname or email (see VCardFieldNames), or
+// ejabberd field name and subname separated by a dot, like N.FAMILY.
+func ParseVCardField(s string) (VCardField, error) {
+	if f, ok := vcardFields[strings.ToLower(s)]; ok {
+		return f, nil
+	}
+
+	parts := strings.SplitN(s, ".", 2)
+	if parts[0] == "" || strings.ToUpper(s) != s {
+		return VCardField{}, fmt.Errorf("unknown vcard field: %s", s)
+	}
+	f := VCardField{Name: parts[0]}
+	if len(parts) == 2 {
+		f.Subname = parts[1]
+	}
+	return f, nil
+}
+
+//==============================================================================
+
+// VCardValue contains the value of a vCard field, as returned by
+// ejabberd get_vcard and get_vcard2 API.
+type VCardValue struct {
+	JID   string     `json:"jid"`
+	Field VCardField `json:"field"`
+	Value string     `json:"value"`
+}
+
+// JSON represents VCardValue as a JSON string, for further processing
+// with other tools.
+func (v VCardValue) JSON() string {
+	body, _ := json.Marshal(v)
+	return string(body)
+}
+
+func (v VCardValue) String() string {
+	return v.Value
+}
+
+// VCardValues contains the values of a vCard field that can be
+// repeated, as returned by ejabberd get_vcard2_multi API.
+type VCardValues struct {
+	JID    string     `json:"jid"`
+	Field  VCardField `json:"field"`
+	Values []string   `json:"values"`
+}
+
+// JSON represents VCardValues as a JSON string, for further
+// processing with other tools.
+func (v VCardValues) JSON() string {
+	body, _ := json.Marshal(v)
+	return string(body)
+}
+
+// String represents VCardValues with one value per line.
+func (v VCardValues) String() string {
+	return strings.Join(v.Values, "\n")
+}
+
+//==============================================================================
+
+// vcardArgs are the arguments common to all vCard commands.
+type vcardArgs struct {
+	User    string `json:"user"`
+	Host    string `json:"host"`
+	Name    string `json:"name"`
+	Subname string `json:"subname,omitempty"`
+}
+
+// newVCardArgs prepares arguments for a vCard command on field of
+// user bareJID. Commands with a 2 suffix require a subname.
+func newVCardArgs(bareJID string, field VCardField, needSubname bool) (vcardArgs, error) {
+	jid, err := parseJID(bareJID)
+	if err != nil {
+		return vcardArgs{}, err
+	}
+	if field.Name == "" {
+		return vcardArgs{}, fmt.Errorf("required vcard field name not provided")
+	}
+	if needSubname && field.Subname == "" {
+		return vcardArgs{}, fmt.Errorf("vcard field %s has no subname", field)
+	}
+
+	return vcardArgs{
+		User:    jid.username,
+		Host:    jid.domain,
+		Name:    field.Name,
+		Subname: field.Subname,
+	}, nil
+}
+
+//==============================================================================
+
+type getVCardRequest struct {
+	JID   string     `json:"jid"`
+	Field VCardField `json:"field"`
+}
+
+func (g getVCardRequest) command() string {
+	if g.Field.Subname == "" {
+		return "get_vcard"
+	}
+	return "get_vcard2"
+}
+
+func (g getVCardRequest) params() (apiParams, error) {
+	args, err := newVCardArgs(g.JID, g.Field, false)
+	if err != nil {
+		return apiParams{}, err
+	}
+
+	p, err := commandParams(g.command(), 1, args)
+	p.idempotent = true
+	return p, err
+}
+
+func (g getVCardRequest) parseResponse(body []byte) (Response, error) {
+	resp := VCardValue{JID: g.JID, Field: g.Field}
+	err := decodeResponse(g.command(), body, &resp.Value)
+	return resp, err
+}
+
+// GetVCard returns the value of a vCard field of a user, for example
+// VCardFullName or VCardEmail. It can be called as a user, for your
+// own vCard, or as an admin for any user.
+func (c Client) GetVCard(bareJID string, field VCardField) (VCardValue, error) {
+	return c.GetVCardContext(context.Background(), bareJID, field)
+}
+
+// GetVCardContext is like GetVCard but carries ctx into the API call.
+func (c Client) GetVCardContext(ctx context.Context, bareJID string, field VCardField) (VCardValue, error) {
+	result, err := c.call(ctx, getVCardRequest{JID: bareJID, Field: field})
+	if err != nil {
+		return VCardValue{}, err
+	}
+	resp := result.(VCardValue)
+	return resp, nil
+}
+
+//==============================================================================
+
+type getVCardMultiRequest struct {
+	JID   string     `json:"jid"`
+	Field VCardField `json:"field"`
+}
+
+func (g getVCardMultiRequest) params() (apiParams, error) {
+	args, err := newVCardArgs(g.JID, g.Field, true)
+	if err != nil {
+		return apiParams{}, err
+	}
+
+	p, err := commandParams("get_vcard2_multi", 1, args)
+	p.idempotent = true
+	return p, err
+}
+
+func (g getVCardMultiRequest) parseResponse(body []byte) (Response, error) {
+	resp := VCardValues{JID: g.JID, Field: g.Field}
+	err := decodeResponse("get_vcard2_multi", body, &resp.Values)
+	return resp, err
+}
+
+// GetVCardMulti returns all the values of a repeated vCard field of a
+// user, like VCardEmail or VCardTelephone. Field must have a subname.
+func (c Client) GetVCardMulti(bareJID string, field VCardField) (VCardValues, error) {
+	return c.GetVCardMultiContext(context.Background(), bareJID, field)
+}
+
+// GetVCardMultiContext is like GetVCardMulti but carries ctx into the
+// API call.
+func (c Client) GetVCardMultiContext(ctx context.Context, bareJID string, field VCardField) (VCardValues, error) {
+	result, err := c.call(ctx, getVCardMultiRequest{JID: bareJID, Field: field})
+	if err != nil {
+		return VCardValues{}, err
+	}
+	resp := result.(VCardValues)
+	return resp, nil
+}
+
+//==============================================================================
+
+type setVCardRequest struct {
+	JID   string     `json:"jid"`
+	Field VCardField `json:"field"`
+	Value string     `json:"value"`
+}
+
+func (s setVCardRequest) command() string {
+	if s.Field.Subname == "" {
+		return "set_vcard"
+	}
+	return "set_vcard2"
+}
+
+func (s setVCardRequest) params() (apiParams, error) {
+	args, err := newVCardArgs(s.JID, s.Field, false)
+	if err != nil {
+		return apiParams{}, err
+	}
+
+	type setVCard struct {
+		vcardArgs
+		Content string `json:"content"`
+	}
+
+	return commandParams(s.command(), 1, setVCard{vcardArgs: args, Content: s.Value})
+}
+
+func (s setVCardRequest) parseResponse(body []byte) (Response, error) {
+	return parseAction(s.command(), body)
+}
+
+// SetVCard sets the value of a vCard field of a user. It can be
+// called as a user, for your own vCard, or as an admin for any user.
+func (c Client) SetVCard(bareJID string, field VCardField, value string) error {
+	return c.SetVCardContext(context.Background(), bareJID, field, value)
+}
+
+// SetVCardContext is like SetVCard but carries ctx into the API call.
+func (c Client) SetVCardContext(ctx context.Context, bareJID string, field VCardField, value string) error {
+	_, err := c.call(ctx, setVCardRequest{JID: bareJID, Field: field, Value: value})
+	return err
+}
+
+//==============================================================================
+
+type setVCardMultiRequest struct {
+	JID    string     `json:"jid"`
+	Field  VCardField `json:"field"`
+	Values []string   `json:"values"`
+}
+
+func (s setVCardMultiRequest) params() (apiParams, error) {
+	args, err := newVCardArgs(s.JID, s.Field, true)
+	if err != nil {
+		return apiParams{}, err
+	}
+
+	type setVCardMulti struct {
+		vcardArgs
+		Contents []string `json:"contents"`
+	}
+
+	values := s.Values
+	if values == nil {
+		values = []string{}
+	}
+	return commandParams("set_vcard2_multi", 1, setVCardMulti{vcardArgs: args, Contents: values})
+}
+
+func (s setVCardMultiRequest) parseResponse(body []byte) (Response, error) {
+	return parseAction("set_vcard2_multi", body)
+}
+
+// SetVCardMulti sets all the values of a repeated vCard field of a
+// user. Field must have a subname.
+func (c Client) SetVCardMulti(bareJID string, field VCardField, values []string) error {
+	return c.SetVCardMultiContext(context.Background(), bareJID, field, values)
+}
+
+// SetVCardMultiContext is like SetVCardMulti but carries ctx into the
+// API call.
+func (c Client) SetVCardMultiContext(ctx context.Context, bareJID string, field VCardField, values []string) error {
+	_, err := c.call(ctx, setVCardMultiRequest{JID: bareJID, Field: field, Values: values})
+	return err
+}
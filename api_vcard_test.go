@@ -0,0 +1,55 @@
+package ejabberd
+
+import "testing"
+
+func TestParseVCardField(t *testing.T) {
+	var tests = []struct {
+		input string
+		want  VCardField
+		err   error
+	}{
+		{"fullname", VCardFullName, nil},
+		{"Email", VCardEmail, nil},
+		{"N.FAMILY", VCardFamilyName, nil},
+		{"X-CUSTOM", VCardField{Name: "X-CUSTOM"}, nil},
+		{"unknown", VCardField{}, testError{}},
+		{".GIVEN", VCardField{}, testError{}},
+	}
+	for _, test := range tests {
+		got, err := ParseVCardField(test.input)
+		if (err != nil) != (test.err != nil) {
+			t.Errorf("ParseVCardField(%q) error = %v", test.input, err)
+			continue
+		}
+		if got != test.want {
+			t.Errorf("ParseVCardField(%q) = %v, want %v", test.input, got, test.want)
+		}
+	}
+}
+
+func TestVCardCommand(t *testing.T) {
+	var tests = []struct {
+		req  request
+		want string
+	}{
+		{getVCardRequest{JID: "user@localhost", Field: VCardNickname}, "get_vcard"},
+		{getVCardRequest{JID: "user@localhost", Field: VCardGivenName}, "get_vcard2"},
+		{setVCardRequest{JID: "user@localhost", Field: VCardFullName, Value: "User"}, "set_vcard"},
+		{setVCardRequest{JID: "user@localhost", Field: VCardPhoto, Value: "iVBORw0K"}, "set_vcard2"},
+		{setVCardMultiRequest{JID: "user@localhost", Field: VCardEmail}, "set_vcard2_multi"},
+	}
+	for _, test := range tests {
+		p, err := test.req.params()
+		if err != nil {
+			t.Errorf("error on params for %+v: %s", test.req, err)
+			continue
+		}
+		if p.name != test.want {
+			t.Errorf("Incorrect command %s for %+v, want %s", p.name, test.req, test.want)
+		}
+	}
+
+	if _, err := (getVCardMultiRequest{JID: "user@localhost", Field: VCardNickname}).params(); err == nil {
+		t.Errorf("get_vcard2_multi should require a subname")
+	}
+}
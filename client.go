@@ -2,8 +2,11 @@ package ejabberd
 
 import (
 	"bytes"
+	"context"
 	"fmt"
+	"io"
 	"io/ioutil"
+	"log/slog"
 	"net"
 	"net/http"
 	"reflect"
@@ -21,15 +24,55 @@ type Client struct {
 	OAuthPath  string
 	APIPath    string
 	HTTPClient *http.Client
+
+	// APIVersion is the ejabberd API version used by CallRaw and
+	// CallStream. Zero lets the server pick its default version.
+	// Typed commands always use the version they have been written
+	// for, see "API versions" in package documentation.
+	APIVersion int
+
+	// MaxResponseSize is the maximum number of bytes read from an
+	// ejabberd response body. Zero means DefaultMaxResponseSize and a
+	// negative value disables the limit. Streamed calls are not
+	// bounded.
+	MaxResponseSize int64
+
+	// Retry defines how failed calls are retried. Nil disables
+	// retries.
+	Retry *RetryPolicy
+
+	// Limiter bounds the rate and concurrency of calls. Nil disables
+	// limits.
+	Limiter *Limiter
+
+	// Middleware wraps each attempt to call ejabberd API. The first
+	// middleware is the outermost one.
+	Middleware []Middleware
+
+	// Logger receives a record for each request sent to ejabberd,
+	// with bearer token and passwords redacted. Nil disables logging.
+	Logger *slog.Logger
+
+	// Tracer creates a span for each API command and token request.
+	// Nil disables tracing.
+	Tracer Tracer
 }
 
+// DefaultMaxResponseSize is the response size limit used when
+// Client.MaxResponseSize is not set.
+const DefaultMaxResponseSize = 10 << 20
+
 //==============================================================================
 
 // Generic Call functions
 
 // call performs HTTP call to ejabberd API given client parameters. It
-// returns a struct complying with Response interface.
-func (c Client) call(req request) (Response, error) {
+// returns a struct complying with Response interface. Server errors
+// are returned as APIError, network errors as TransportError and
+// invalid responses as DecodeError. If ctx is canceled or its deadline
+// expires before the call completes, the context error is returned as
+// is.
+func (c Client) call(ctx context.Context, req request) (Response, error) {
 	p, err := req.params()
 	if err != nil {
 		return nil, err
@@ -42,55 +85,232 @@ func (c Client) call(req request) (Response, error) {
 		admin = true
 	}
 
-	code, result, err := c.CallRaw(p.body, p.name, admin)
+	ctx, finish := c.startSpan(ctx, p, admin)
+	code, result, err := c.do(ctx, p, admin)
 	if err != nil {
-		return APIError{Code: 99}, err
+		finish(code, err)
+		return nil, err
 	}
 
 	if code != 200 {
-		apiError, err := parseError(result)
-		if err != nil {
-			return nil, err
-		}
-		return nil, apiError
+		err = parseError(p.name, code, result)
+		finish(code, err)
+		return nil, err
 	}
 
-	return req.parseResponse(result)
+	resp, err := req.parseResponse(result)
+	finish(code, err)
+	return resp, err
 }
 
 // CallRaw performs HTTP call to ejabberd API and returns Raw Body
 // reponse from the server as slice of bytes.
 func (c Client) CallRaw(body []byte, name string, admin bool) (code int, result []byte, err error) {
+	return c.CallRawContext(context.Background(), body, name, admin)
+}
+
+// CallRawContext is like CallRaw but carries ctx into the HTTP
+// request, so the call can be canceled or bound to a deadline. When
+// the call is aborted because of ctx, the returned error is
+// ctx.Err(), that is context.Canceled or context.DeadlineExceeded.
+func (c Client) CallRawContext(ctx context.Context, body []byte, name string, admin bool) (code int, result []byte, err error) {
+	p := rawParams(name, c.APIVersion, body)
+	ctx, finish := c.startSpan(ctx, p, admin)
+	code, result, err = c.do(ctx, p, admin)
+	finish(code, err)
+	return code, result, err
+}
+
+// rawParams prepares parameters for generic calls. Calls without body
+// are sent as GET and considered idempotent.
+func rawParams(name string, version int, body []byte) apiParams {
+	p := apiParams{
+		name:    name,
+		version: version,
+		method:  "POST",
+		body:    body,
+	}
+	if len(body) == 0 {
+		p.method = "GET"
+		p.idempotent = true
+	}
+	return p
+}
+
+// do performs the HTTP request described by p: HTTP method, query
+// string, API version and body. It returns the HTTP status code and
+// the raw body of the response. The body is read up to the client
+// maximum response size.
+func (c Client) do(ctx context.Context, p apiParams, admin bool) (code int, result []byte, err error) {
+	res, err := c.exchange(ctx, p, admin, false)
+	if res == nil {
+		return 0, []byte{}, err
+	}
+	return res.StatusCode, res.Body, err
+}
+
+// exchange performs the call described by p through the client
+// middlewares, retrying according to the client retry policy and
+// waiting for the client limiter before each attempt. When stream is
+// true and the server replies with HTTP status 200, the response body
+// is not read and is left open for the caller in the result.
+func (c Client) exchange(ctx context.Context, p apiParams, admin bool, stream bool) (*APIResult, error) {
 	if c.HTTPClient == nil {
 		c.HTTPClient = defaultHTTPClient(15 * time.Second)
 	}
 
-	var url string
-	if url, err = apiURL(c.BaseURL, c.APIPath, name); err != nil {
-		return 0, []byte{}, err
+	handler := c.transport(stream)
+	if c.Logger != nil {
+		handler = c.logMiddleware(c.Logger)(handler)
 	}
-	var r *http.Request
-	if len(body) == 0 {
-		r, _ = http.NewRequest("GET", url, nil)
-	} else {
-		r, _ = http.NewRequest("POST", url, bytes.NewBuffer(body))
+	for i := len(c.Middleware) - 1; i >= 0; i-- {
+		handler = c.Middleware[i](handler)
 	}
-	r.Header.Set("Authorization", fmt.Sprintf("Bearer %s", c.Token.AccessToken))
-	r.Header.Set("Content-Type", "application/json")
+
+	for attempt := 1; ; attempt++ {
+		release, err := c.Limiter.acquire(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		res, err := handler(ctx, c.newCall(ctx, p, admin, attempt))
+		if res != nil && res.stream != nil {
+			res.stream = &releaseBody{ReadCloser: res.stream, release: release}
+		} else {
+			release()
+		}
+		if ctx.Err() != nil {
+			return res, err
+		}
+
+		delay, retry := c.Retry.backoff(attempt, p.idempotent, res, err)
+		if !retry {
+			return res, err
+		}
+		if res != nil && res.stream != nil {
+			res.stream.Close()
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// newCall prepares the description of an attempt to call p.
+func (c Client) newCall(ctx context.Context, p apiParams, admin bool, attempt int) *APICall {
+	method := p.method
+	if method == "" {
+		method = "POST"
+	}
+
+	header := make(http.Header)
+	header.Set("Authorization", fmt.Sprintf("Bearer %s", c.Token.AccessToken))
+	header.Set("Content-Type", "application/json")
 	if admin {
-		r.Header.Set("X-Admin", "true")
+		header.Set("X-Admin", "true")
+	}
+	if tp := traceParentFromContext(ctx); tp != "" {
+		header.Set("Traceparent", tp)
 	}
 
-	resp, err := c.HTTPClient.Do(r)
-	if err != nil {
-		return 0, []byte{}, err
+	return &APICall{
+		Command: p.name,
+		Version: p.version,
+		Admin:   admin,
+		Attempt: attempt,
+		Method:  method,
+		Query:   p.query,
+		Header:  header,
+		Body:    p.body,
+	}
+}
+
+// transport returns the innermost CallHandler, sending the HTTP
+// request described by the call to ejabberd.
+func (c Client) transport(stream bool) CallHandler {
+	return func(ctx context.Context, call *APICall) (*APIResult, error) {
+		url, err := apiURL(c.BaseURL, c.APIPath, call.Command, call.Version)
+		if err != nil {
+			return nil, err
+		}
+		if len(call.Query) > 0 {
+			url += "?" + call.Query.Encode()
+		}
+
+		var body io.Reader
+		if call.Method != "GET" && len(call.Body) > 0 {
+			body = bytes.NewReader(call.Body)
+		}
+
+		r, err := http.NewRequestWithContext(ctx, call.Method, url, body)
+		if err != nil {
+			return nil, err
+		}
+		r.Header = call.Header
+
+		start := time.Now()
+		resp, err := c.HTTPClient.Do(r)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			return nil, TransportError{Command: call.Command, Err: err}
+		}
+
+		res := &APIResult{
+			StatusCode: resp.StatusCode,
+			Header:     resp.Header,
+		}
+		if stream && resp.StatusCode == 200 {
+			res.Streamed = true
+			res.stream = resp.Body
+			res.Duration = time.Since(start)
+			return res, nil
+		}
+
+		res.Body, err = c.readBody(call.Command, resp.Body)
+		resp.Body.Close()
+		res.Duration = time.Since(start)
+		if err != nil {
+			if ctx.Err() != nil {
+				err = ctx.Err()
+			} else if _, ok := err.(ResponseTooLargeError); !ok {
+				err = TransportError{Command: call.Command, Err: err}
+			}
+		}
+		return res, err
 	}
+}
 
-	// TODO: We should limit the amount of data the client reads from ejabberd as response
-	result, err = ioutil.ReadAll(resp.Body)
-	resp.Body.Close()
+// readBody reads a response body for command name, failing with
+// ResponseTooLargeError if it is bigger than the client maximum
+// response size.
+func (c Client) readBody(name string, r io.Reader) ([]byte, error) {
+	limit := c.maxResponseSize()
+	if limit < 0 {
+		return ioutil.ReadAll(r)
+	}
 
-	return resp.StatusCode, result, err
+	result, err := ioutil.ReadAll(io.LimitReader(r, limit+1))
+	if err != nil {
+		return result, err
+	}
+	if int64(len(result)) > limit {
+		return result[:limit], ResponseTooLargeError{Command: name, Limit: limit}
+	}
+	return result, nil
+}
+
+func (c Client) maxResponseSize() int64 {
+	if c.MaxResponseSize == 0 {
+		return DefaultMaxResponseSize
+	}
+	return c.MaxResponseSize
 }
 
 // Check if Request struct has a field call JID.
@@ -140,6 +360,12 @@ func needAdminForUser(command interface{}, JID string) bool {
 // to generate a token. In case of doubt you need to check ejabberd
 // access option 'oauth_access'.
 func (c Client) GetToken(sjid, password, scope string, duration time.Duration) (OAuthToken, error) {
+	return c.GetTokenContext(context.Background(), sjid, password, scope, duration)
+}
+
+// GetTokenContext is like GetToken but carries ctx into the HTTP
+// request, so that the token request can be canceled.
+func (c Client) GetTokenContext(ctx context.Context, sjid, password, scope string, duration time.Duration) (OAuthToken, error) {
 	var j jid
 	var t OAuthToken
 	var err error
@@ -163,7 +389,14 @@ func (c Client) GetToken(sjid, password, scope string, duration time.Duration) (
 	params := tokenParams(j, password, prepareScope(scope), strconv.Itoa(ttl))
 
 	// Request token from server
-	if t, err = httpGetToken(c.HTTPClient, u, params); err != nil {
+	ctx, finish := c.startTokenSpan(ctx, j)
+	start := time.Now()
+	t, err = httpGetToken(ctx, c.HTTPClient, u, params)
+	finish(err)
+	if c.Logger != nil {
+		logToken(ctx, c.Logger, u, j, scope, time.Since(start), err)
+	}
+	if err != nil {
 		return t, err
 	}
 	return t, nil
@@ -179,11 +412,16 @@ func (c Client) GetToken(sjid, password, scope string, duration time.Duration) (
 //     uptimeseconds
 //     processes
 func (c Client) Stats(name string) (Stats, error) {
+	return c.StatsContext(context.Background(), name)
+}
+
+// StatsContext is like Stats but carries ctx into the API call.
+func (c Client) StatsContext(ctx context.Context, name string) (Stats, error) {
 	command := statsRequest{
 		Name: name,
 	}
 
-	result, err := c.call(command)
+	result, err := c.call(ctx, command)
 	if err != nil {
 		return Stats{}, err
 	}
@@ -198,11 +436,17 @@ func (c Client) Stats(name string) (Stats, error) {
 // already exists, or if the user performing the registration does not
 // have the right to create new users on the server or domain.
 func (c Client) RegisterUser(bareJID string, password string) (Register, error) {
+	return c.RegisterUserContext(context.Background(), bareJID, password)
+}
+
+// RegisterUserContext is like RegisterUser but carries ctx into the
+// API call.
+func (c Client) RegisterUserContext(ctx context.Context, bareJID string, password string) (Register, error) {
 	command := registerRequest{
 		JID:      bareJID,
 		Password: password}
 
-	result, err := c.call(command)
+	result, err := c.call(ctx, command)
 	if err != nil {
 		return "", err
 	}
@@ -218,11 +462,17 @@ func (c Client) RegisterUser(bareJID string, password string) (Register, error)
 // and in that case, you can read offline message count from any user
 // on the server.
 func (c Client) GetOfflineCount(bareJID string) (OfflineCount, error) {
+	return c.GetOfflineCountContext(context.Background(), bareJID)
+}
+
+// GetOfflineCountContext is like GetOfflineCount but carries ctx into
+// the API call.
+func (c Client) GetOfflineCountContext(ctx context.Context, bareJID string) (OfflineCount, error) {
 	command := offlineCountRequest{
 		JID: bareJID,
 	}
 
-	result, err := c.call(command)
+	result, err := c.call(ctx, command)
 	if err != nil {
 		return OfflineCount{}, err
 	}
@@ -238,11 +488,17 @@ func (c Client) GetOfflineCount(bareJID string) (OfflineCount, error) {
 // case, you can read the connected resources for any any user on the
 // server.
 func (c Client) UserResources(bareJID string) (UserResources, error) {
+	return c.UserResourcesContext(context.Background(), bareJID)
+}
+
+// UserResourcesContext is like UserResources but carries ctx into the
+// API call.
+func (c Client) UserResourcesContext(ctx context.Context, bareJID string) (UserResources, error) {
 	command := userResourcesRequest{
 		JID: bareJID,
 	}
 
-	result, err := c.call(command)
+	result, err := c.call(ctx, command)
 	if err != nil {
 		return UserResources{}, err
 	}
@@ -1,16 +1,67 @@
 package ejabberd_test
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/processone/ejabberd-api"
 )
 
+// apiCall is a command call received by the test API server.
+type apiCall struct {
+	command string
+	admin   bool
+	args    map[string]interface{}
+}
+
+// newAPIServer starts a test ejabberd API server, replying to each
+// command call with the given status code and body.
+func newAPIServer(t *testing.T, reply func(call apiCall) (int, string)) (*httptest.Server, *[]apiCall) {
+	var calls []apiCall
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		call := apiCall{
+			command: strings.Split(strings.TrimPrefix(r.URL.Path, "/api/"), "/")[0],
+			admin:   r.Header.Get("X-Admin") == "true",
+		}
+		if r.ContentLength > 0 {
+			if err := json.NewDecoder(r.Body).Decode(&call.args); err != nil {
+				t.Errorf("invalid request body for %s: %s", call.command, err)
+			}
+		}
+		calls = append(calls, call)
+		code, body := reply(call)
+		w.WriteHeader(code)
+		fmt.Fprint(w, body)
+	}))
+	return server, &calls
+}
+
+// checkCalls compares calls received by test API server with the
+// expected ones.
+func checkCalls(t *testing.T, got, want []apiCall) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("Incorrect number of calls %d != %d", len(got), len(want))
+	}
+	for i := range want {
+		if got[i].command != want[i].command || got[i].admin != want[i].admin {
+			t.Errorf("Incorrect call %s (admin %t), want %s (admin %t)", got[i].command, got[i].admin, want[i].command, want[i].admin)
+		}
+		if fmt.Sprint(got[i].args) != fmt.Sprint(want[i].args) {
+			t.Errorf("Incorrect arguments for %s: %v, want %v", want[i].command, got[i].args, want[i].args)
+		}
+	}
+}
+
 func Test_GetToken(t *testing.T) {
 	accessToken := "12345"
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -38,6 +89,45 @@ func Test_GetToken(t *testing.T) {
 	}
 }
 
+func Test_StatsContextCanceled(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer server.Close()
+	defer close(release)
+
+	client := ejabberd.Client{BaseURL: server.URL}
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	_, err := client.StatsContext(ctx, "registeredusers")
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("StatsContext error = %v, want %v", err, context.DeadlineExceeded)
+	}
+}
+
+func Test_StatsVersionedPath(t *testing.T) {
+	var method, path string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		method, path = r.Method, r.URL.Path
+		fmt.Fprintln(w, `{"stat": 42}`)
+	}))
+	defer server.Close()
+
+	client := ejabberd.Client{BaseURL: server.URL}
+	stats, err := client.Stats("registeredusers")
+	if err != nil {
+		t.Fatalf("Stats failed: %s", err)
+	}
+	if stats.Value != 42 {
+		t.Errorf("Incorrect stats value %d != 42", stats.Value)
+	}
+	if method != "POST" || path != "/api/stats/v1" {
+		t.Errorf("Incorrect request %s %s", method, path)
+	}
+}
+
 // TODO provide const to specify token duration
 
 func ExampleClient_GetToken() {
@@ -3,16 +3,19 @@ package main
 import (
 	"fmt"
 	"io/ioutil"
+	"log/slog"
 	"os"
+	"strings"
 
 	"github.com/processone/ejabberd-api"
 	"github.com/alecthomas/kingpin/v2"
 )
 
 var (
-	app  = kingpin.New("ejabberd", "A command-line front-end for ejabberd server API.").Version("0.0.1").Author("ProcessOne")
-	file = app.Flag("file", "OAuth token JSON file.").Short('f').Default(".ejabberd-oauth.json").String()
-	json = app.Flag("json", "JSON formatted output").Bool()
+	app     = kingpin.New("ejabberd", "A command-line front-end for ejabberd server API.").Version("0.0.1").Author("ProcessOne")
+	file    = app.Flag("file", "OAuth token JSON file.").Short('f').Default(".ejabberd-oauth.json").String()
+	json    = app.Flag("json", "JSON formatted output").Bool()
+	verbose = app.Flag("verbose", "Log API requests and responses on stderr").Short('v').Bool()
 
 	// ========= token =========
 	token         = app.Command("token", "Request an OAuth token.")
@@ -34,21 +37,35 @@ var (
 	registerPassword = register.Flag("password", "Password to set for created user.").Short('p').Required().String()
 
 	// ========= user =========
-	user          = app.Command("user", "Operations to perform on users.")
-	userOperation = user.Arg("operation", "Operation").Required().Enum("resources")
-	userJID       = user.Flag("jid", "JID of the user to perform operation on.").Short('j').String()
+	user           = app.Command("user", "Operations to perform on users.")
+	userOperation  = user.Arg("operation", "Operation").Required().Enum("resources", "unregister", "change-password", "check", "check-password", "check-password-hash", "ban", "unban", "ban-status", "sessions", "kick", "presence", "last", "blocklist", "block", "unblock")
+	userJID        = user.Flag("jid", "JID of the user to perform operation on.").Short('j').String()
+	userPassword   = user.Flag("password", "Password for change-password and check-password operations.").Short('p').String()
+	userHash       = user.Flag("hash", "Password hash for check-password-hash operation.").String()
+	userHashMethod = user.Flag("hash-method", "Hash method for check-password-hash operation, for example md5 or sha.").Default("sha").String()
+	userReason     = user.Flag("reason", "Reason for ban and kick operations.").String()
+	userBlock      = user.Flag("block-jid", "JID to block or unblock. Can be repeated.").Strings()
+	userUnblockAll = user.Flag("all", "Empty the whole block list, for unblock operation.").Bool()
+	userYes        = user.Flag("yes", "Do not ask for confirmation before emptying the block list.").Short('y').Bool()
+
+	// ========= users =========
+	users          = app.Command("users", "Operations to perform on the set of registered users.")
+	usersOperation = users.Arg("operation", "Operation").Required().Enum("list", "vhosts", "connected")
+	usersHost      = users.Flag("host", "Virtual host to list users from. Defaults to token owner domain for list, and to all hosts for connected").String()
 
 	// ========= offline =========
 	offline          = app.Command("offline", "Operations to perform on offline store.")
-	offlineOperation = offline.Arg("operation", "Operation").Required().Enum("count")
+	offlineOperation = offline.Arg("operation", "Operation").Required().Enum("count", "list", "delete", "purge")
 	offlineJID       = offline.Flag("jid", "JID of the user to perform operation on, if different from token owner").Short('j').String()
+	offlineDays      = offline.Flag("days", "Age in days of the messages to remove, for purge operation").Int()
 
 	// ========= generic call =========
-	call      = app.Command("call", "Call a command on ejabberd server, using your token credentials.")
-	callFile  = call.Flag("data-file", "File with JSON data to send to ejabberd. You can also use /dev/stdin").String()
-	callData  = call.Flag("data", "File with JSON data to send to ejabberd. Omit to read from STDIN").String()
-	callName  = call.Flag("name", "Name of command on server").Short('n').Required().String()
-	callAdmin = call.Flag("admin", "Call as admin").Short('a').Bool()
+	call        = app.Command("call", "Call a command on ejabberd server, using your token credentials.")
+	callFile    = call.Flag("data-file", "File with JSON data to send to ejabberd. You can also use /dev/stdin").String()
+	callData    = call.Flag("data", "File with JSON data to send to ejabberd. Omit to read from STDIN").String()
+	callName    = call.Flag("name", "Name of command on server").Short('n').Required().String()
+	callAdmin   = call.Flag("admin", "Call as admin").Short('a').Bool()
+	callVersion = call.Flag("api-version", "Version of the command API to call. Omit to use server default").Int()
 )
 
 func main() {
@@ -76,10 +93,13 @@ func execute(command string) {
 		BaseURL: t.Endpoint,
 		APIPath: "api/",
 		Token:   t,
+		Logger:  logger(),
 	}
 
 	switch command {
 	case call.FullCommand():
+		c.APIVersion = *callVersion
+		c.MaxResponseSize = -1
 		genericCommand(c, *callName, *callData, *callFile, *callAdmin)
 	case register.FullCommand():
 		registerCommand(c, *registerJID, *registerPassword)
@@ -87,12 +107,38 @@ func execute(command string) {
 		statsCommand(c)
 	case user.FullCommand():
 		userCommand(c, *userOperation)
+	case users.FullCommand():
+		usersCommand(c, *usersOperation)
+	case roster.FullCommand():
+		rosterCommand(c, *rosterOperation)
+	case vcard.FullCommand():
+		vcardCommand(c, *vcardOperation)
+	case mam.FullCommand():
+		mamCommand(c, *mamOperation)
+	case muc.FullCommand():
+		mucCommand(c, *mucOperation)
+	case server.FullCommand():
+		serverCommand(c, *serverOperation)
+	case srg.FullCommand():
+		srgCommand(c, *srgOperation)
 	case offline.FullCommand():
 		offlineCommand(c, *offlineOperation)
+	case sendMessage.FullCommand():
+		sendMessageCommand(c)
+	case sendStanza.FullCommand():
+		sendStanzaCommand(c)
 	}
 
 }
 
+// logger returns a debug logger writing on stderr in verbose mode.
+func logger() *slog.Logger {
+	if !*verbose {
+		return nil
+	}
+	return slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelDebug}))
+}
+
 func format(resp ejabberd.Response) {
 	if *json {
 		fmt.Println(resp.JSON())
@@ -106,7 +152,7 @@ func format(resp ejabberd.Response) {
 func getToken() {
 	var token ejabberd.OAuthToken
 	var err error
-	client := ejabberd.Client{BaseURL: *tokenEndpoint, OAuthPath: *tokenOauthURL}
+	client := ejabberd.Client{BaseURL: *tokenEndpoint, OAuthPath: *tokenOauthURL, Logger: logger()}
 	if token, err = client.GetToken(*tokenJID, *tokenPassword, *tokenScope, *tokenTTL); err != nil {
 		kingpin.Fatalf("could not retrieve token: %s", err)
 	}
@@ -145,6 +191,36 @@ func userCommand(c ejabberd.Client, op string) {
 	switch op {
 	case "resources":
 		resourcesCommand(c, *userJID)
+	case "unregister":
+		unregisterCommand(c, *userJID)
+	case "change-password":
+		changePasswordCommand(c, *userJID, *userPassword)
+	case "check":
+		checkAccountCommand(c, *userJID)
+	case "check-password":
+		checkPasswordCommand(c, *userJID, *userPassword)
+	case "check-password-hash":
+		checkPasswordHashCommand(c, *userJID, *userHash, *userHashMethod)
+	case "ban":
+		banCommand(c, *userJID, *userReason)
+	case "unban":
+		unbanCommand(c, *userJID)
+	case "ban-status":
+		banStatusCommand(c, *userJID)
+	case "sessions":
+		sessionsCommand(c, *userJID)
+	case "kick":
+		kickCommand(c, *userJID, *userReason)
+	case "presence":
+		presenceCommand(c, *userJID)
+	case "last":
+		lastCommand(c, *userJID)
+	case "blocklist":
+		blockListCommand(c, *userJID)
+	case "block":
+		blockCommand(c, *userJID, *userBlock)
+	case "unblock":
+		unblockCommand(c, *userJID, *userBlock, *userUnblockAll)
 	}
 }
 
@@ -160,12 +236,278 @@ func resourcesCommand(c ejabberd.Client, jid string) {
 	format(resp)
 }
 
+func unregisterCommand(c ejabberd.Client, jid string) {
+	if jid == "" {
+		kingpin.Fatalf("jid of the user to unregister is required")
+	}
+
+	resp, err := c.UnregisterUser(jid)
+	if err != nil {
+		kingpin.Fatalf("user unregistration error for %s: %s", jid, err)
+	}
+	format(resp)
+}
+
+func changePasswordCommand(c ejabberd.Client, jid, password string) {
+	if jid == "" {
+		jid = c.Token.JID
+	}
+	if password == "" {
+		kingpin.Fatalf("new password is required")
+	}
+
+	if err := c.ChangePassword(jid, password); err != nil {
+		kingpin.Fatalf("change password error for %s: %s", jid, err)
+	}
+}
+
+func checkAccountCommand(c ejabberd.Client, jid string) {
+	if jid == "" {
+		jid = c.Token.JID
+	}
+
+	resp, err := c.CheckAccount(jid)
+	if err != nil {
+		kingpin.Fatalf("check account error for %s: %s", jid, err)
+	}
+	format(resp)
+}
+
+func checkPasswordCommand(c ejabberd.Client, jid, password string) {
+	if jid == "" {
+		jid = c.Token.JID
+	}
+	if password == "" {
+		kingpin.Fatalf("password is required")
+	}
+
+	resp, err := c.CheckPassword(jid, password)
+	if err != nil {
+		kingpin.Fatalf("check password error for %s: %s", jid, err)
+	}
+	format(resp)
+}
+
+func checkPasswordHashCommand(c ejabberd.Client, jid, hash, method string) {
+	if jid == "" {
+		jid = c.Token.JID
+	}
+
+	resp, err := c.CheckPasswordHash(jid, hash, method)
+	if err != nil {
+		kingpin.Fatalf("check password hash error for %s: %s", jid, err)
+	}
+	format(resp)
+}
+
+func banCommand(c ejabberd.Client, jid, reason string) {
+	if jid == "" {
+		kingpin.Fatalf("jid of the user to ban is required")
+	}
+	if reason == "" {
+		kingpin.Fatalf("reason of the ban is required")
+	}
+
+	if err := c.BanAccount(jid, reason); err != nil {
+		kingpin.Fatalf("ban error for %s: %s", jid, err)
+	}
+}
+
+func unbanCommand(c ejabberd.Client, jid string) {
+	if jid == "" {
+		kingpin.Fatalf("jid of the user to unban is required")
+	}
+
+	if err := c.UnbanAccount(jid); err != nil {
+		kingpin.Fatalf("unban error for %s: %s", jid, err)
+	}
+}
+
+func banStatusCommand(c ejabberd.Client, jid string) {
+	if jid == "" {
+		jid = c.Token.JID
+	}
+
+	resp, err := c.GetBanDetails(jid)
+	if err != nil {
+		kingpin.Fatalf("ban status error for %s: %s", jid, err)
+	}
+	format(resp)
+}
+
+func sessionsCommand(c ejabberd.Client, jid string) {
+	if jid == "" {
+		jid = c.Token.JID
+	}
+
+	resp, err := c.UserSessions(jid)
+	if err != nil {
+		kingpin.Fatalf("sessions error for %s: %s", jid, err)
+	}
+	format(resp)
+}
+
+// kickCommand disconnects a single session when given a full JID, or
+// all user sessions when given a bare JID.
+func kickCommand(c ejabberd.Client, jid, reason string) {
+	if jid == "" {
+		kingpin.Fatalf("jid of the user or session to kick is required")
+	}
+
+	if strings.Contains(jid, "/") {
+		if err := c.KickSession(jid, reason); err != nil {
+			kingpin.Fatalf("kick error for %s: %s", jid, err)
+		}
+		return
+	}
+
+	resp, err := c.KickUser(jid)
+	if err != nil {
+		kingpin.Fatalf("kick error for %s: %s", jid, err)
+	}
+	format(resp)
+}
+
+func presenceCommand(c ejabberd.Client, jid string) {
+	if jid == "" {
+		jid = c.Token.JID
+	}
+
+	resp, err := c.GetPresence(jid)
+	if err != nil {
+		kingpin.Fatalf("presence error for %s: %s", jid, err)
+	}
+	format(resp)
+}
+
+func lastCommand(c ejabberd.Client, jid string) {
+	if jid == "" {
+		jid = c.Token.JID
+	}
+
+	resp, err := c.GetLast(jid)
+	if err != nil {
+		kingpin.Fatalf("last activity error for %s: %s", jid, err)
+	}
+	format(resp)
+}
+
+func blockListCommand(c ejabberd.Client, jid string) {
+	if jid == "" {
+		jid = c.Token.JID
+	}
+
+	resp, err := c.GetBlockList(jid)
+	if err != nil {
+		kingpin.Fatalf("block list error for %s: %s", jid, err)
+	}
+	format(resp)
+}
+
+func blockCommand(c ejabberd.Client, jid string, blocked []string) {
+	if jid == "" {
+		jid = c.Token.JID
+	}
+
+	if err := c.BlockJIDs(jid, blocked...); err != nil {
+		kingpin.Fatalf("block error for %s: %s", jid, err)
+	}
+}
+
+func unblockCommand(c ejabberd.Client, jid string, blocked []string, all bool) {
+	if jid == "" {
+		jid = c.Token.JID
+	}
+	var err error
+	switch {
+	case all && len(blocked) > 0:
+		kingpin.Fatalf("--all and --block-jid cannot be used together")
+	case all:
+		confirm(*userYes, "Empty the whole block list of %s?", jid)
+		err = c.UnblockAll(jid)
+	case len(blocked) == 0:
+		kingpin.Fatalf("at least one --block-jid is required, or --all to empty the block list")
+	default:
+		err = c.UnblockJIDs(jid, blocked...)
+	}
+	if err != nil {
+		kingpin.Fatalf("unblock error for %s: %s", jid, err)
+	}
+}
+
+//==============================================================================
+
+func usersCommand(c ejabberd.Client, op string) {
+	switch op {
+	case "list":
+		listUsersCommand(c, *usersHost)
+	case "vhosts":
+		vhostsCommand(c)
+	case "connected":
+		connectedUsersCommand(c, *usersHost)
+	}
+}
+
+func listUsersCommand(c ejabberd.Client, host string) {
+	if host == "" {
+		host = c.Token.Domain()
+	}
+
+	// Registered users can be a huge list: do not limit response size
+	// and stream it in text mode.
+	c.MaxResponseSize = -1
+	if *json {
+		resp, err := c.RegisteredUsers(host)
+		if err != nil {
+			kingpin.Fatalf("users list error for %s: %s", host, err)
+		}
+		format(resp)
+		return
+	}
+
+	err := c.EachRegisteredUser(host, func(jid string) error {
+		fmt.Println(jid)
+		return nil
+	})
+	if err != nil {
+		kingpin.Fatalf("users list error for %s: %s", host, err)
+	}
+}
+
+func connectedUsersCommand(c ejabberd.Client, host string) {
+	var resp ejabberd.ConnectedUsers
+	var err error
+	if host == "" {
+		resp, err = c.ConnectedUsers()
+	} else {
+		resp, err = c.ConnectedUsersVHost(host)
+	}
+	if err != nil {
+		kingpin.Fatalf("connected users error: %s", err)
+	}
+	format(resp)
+}
+
+func vhostsCommand(c ejabberd.Client) {
+	resp, err := c.RegisteredVHosts()
+	if err != nil {
+		kingpin.Fatalf("vhosts list error: %s", err)
+	}
+	format(resp)
+}
+
 //==============================================================================
 
 func offlineCommand(c ejabberd.Client, op string) {
 	switch op {
 	case "count":
 		offlineCountCommand(c, *offlineJID)
+	case "list":
+		offlineListCommand(c, *offlineJID)
+	case "delete":
+		offlineDeleteCommand(c, *offlineJID)
+	case "purge":
+		offlinePurgeCommand(c, *offlineDays)
 	}
 }
 
@@ -180,6 +522,35 @@ func offlineCountCommand(c ejabberd.Client, jid string) {
 	format(resp)
 }
 
+func offlineListCommand(c ejabberd.Client, jid string) {
+	if jid == "" {
+		jid = c.Token.JID
+	}
+	resp, err := c.GetOfflineMessages(jid)
+	if err != nil {
+		kingpin.Fatalf("offline list error for %s: %s", jid, err)
+	}
+	format(resp)
+}
+
+func offlineDeleteCommand(c ejabberd.Client, jid string) {
+	if jid == "" {
+		kingpin.Fatalf("jid of the user to delete offline messages for is required")
+	}
+	if err := c.DeleteOfflineMessages(jid); err != nil {
+		kingpin.Fatalf("offline delete error for %s: %s", jid, err)
+	}
+}
+
+func offlinePurgeCommand(c ejabberd.Client, days int) {
+	if days <= 0 {
+		kingpin.Fatalf("number of days is required")
+	}
+	if err := c.DeleteOldMessages(days); err != nil {
+		kingpin.Fatalf("offline purge error: %s", err)
+	}
+}
+
 //==============================================================================
 
 func genericCommand(c ejabberd.Client, commandName, input string, file string, admin bool) {
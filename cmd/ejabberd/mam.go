@@ -0,0 +1,94 @@
+package main
+
+import (
+	"github.com/alecthomas/kingpin/v2"
+	"github.com/processone/ejabberd-api"
+)
+
+var (
+	// ========= mam =========
+	mam          = app.Command("mam", "Maintenance operations on message archives.")
+	mamOperation = mam.Arg("operation", "Operation").Required().Enum("purge", "status", "abort", "remove")
+	mamDays      = mam.Flag("days", "Age in days of the messages to remove, for purge operation.").Int()
+	mamType      = mam.Flag("type", "Type of messages to purge.").Default("all").Enum("all", "chat", "groupchat")
+	mamHost      = mam.Flag("host", "Virtual host to purge in batches, and for status and abort operations. Purge requires --batch-size with --host.").String()
+	mamBatchSize = mam.Flag("batch-size", "Number of messages removed per batch. Requires --host.").Int()
+	mamRate      = mam.Flag("rate", "Maximum number of messages removed per minute, for batch purge.").Default("1000").Int()
+	mamJID       = mam.Flag("jid", "JID of the user whose archive to remove.").Short('j').String()
+	mamPeer      = mam.Flag("peer", "Only remove messages exchanged with this JID.").String()
+)
+
+func mamCommand(c ejabberd.Client, op string) {
+	switch op {
+	case "purge":
+		mamPurgeCommand(c)
+	case "status":
+		resp, err := c.DeleteOldMAMMessagesStatus(*mamHost)
+		if err != nil {
+			kingpin.Fatalf("mam status error: %s", err)
+		}
+		format(resp)
+	case "abort":
+		resp, err := c.AbortDeleteOldMAMMessages(*mamHost)
+		if err != nil {
+			kingpin.Fatalf("mam abort error: %s", err)
+		}
+		format(resp)
+	case "remove":
+		mamRemoveCommand(c, *mamJID, *mamPeer)
+	}
+}
+
+// mamPurgeCommand removes old messages of all virtual hosts in a
+// single operation, or in batches on a single virtual host when host
+// and batch size are given.
+func mamPurgeCommand(c ejabberd.Client) {
+	if *mamDays <= 0 {
+		kingpin.Fatalf("number of days is required")
+	}
+	// Single operation purge applies to all virtual hosts: do not let
+	// --host suggest otherwise.
+	switch {
+	case *mamHost != "" && *mamBatchSize == 0:
+		kingpin.Fatalf("--host requires --batch-size, purge without --host applies to all virtual hosts")
+	case *mamHost == "" && *mamBatchSize != 0:
+		kingpin.Fatalf("--batch-size requires --host")
+	}
+
+	if *mamHost == "" {
+		if err := c.DeleteOldMAMMessages(*mamType, *mamDays); err != nil {
+			kingpin.Fatalf("mam purge error: %s", err)
+		}
+		return
+	}
+
+	resp, err := c.DeleteOldMAMMessagesBatch(ejabberd.MAMBatch{
+		Host:      *mamHost,
+		Type:      *mamType,
+		Days:      *mamDays,
+		BatchSize: *mamBatchSize,
+		Rate:      *mamRate,
+	})
+	if err != nil {
+		kingpin.Fatalf("mam purge error: %s", err)
+	}
+	format(resp)
+}
+
+func mamRemoveCommand(c ejabberd.Client, jid, peer string) {
+	if jid == "" {
+		kingpin.Fatalf("jid of the user whose archive to remove is required")
+	}
+
+	var resp ejabberd.Message
+	var err error
+	if peer == "" {
+		resp, err = c.RemoveMAMForUser(jid)
+	} else {
+		resp, err = c.RemoveMAMForUserWithPeer(jid, peer)
+	}
+	if err != nil {
+		kingpin.Fatalf("mam remove error for %s: %s", jid, err)
+	}
+	format(resp)
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/alecthomas/kingpin/v2"
+	"github.com/processone/ejabberd-api"
+)
+
+var (
+	// ========= muc =========
+	muc            = app.Command("muc", "Operations to perform on multi-user chat rooms.")
+	mucOperation   = muc.Arg("operation", "Operation").Required().Enum("list", "create", "destroy", "options", "set-option", "occupants", "affiliations", "affiliate", "invite")
+	mucRoom        = muc.Flag("room", "JID of the room, like room@conference.localhost.").Short('r').String()
+	mucService     = muc.Flag("service", "MUC service to list rooms from. Defaults to all services.").String()
+	mucHost        = muc.Flag("host", "Virtual host of the room to create. Defaults to the MUC service parent domain.").String()
+	mucOptions     = muc.Flag("option", "Room option as name=value, for create and set-option operations. Can be repeated.").Short('o').Strings()
+	mucJIDs        = muc.Flag("jid", "JID of the user to affiliate or invite. Can be repeated for invite.").Short('j').Strings()
+	mucAffiliation = muc.Flag("affiliation", "Affiliation to set.").Default("member").Enum("owner", "admin", "member", "outcast", "none")
+	mucPassword    = muc.Flag("room-password", "Room password, for invite operation.").String()
+	mucReason      = muc.Flag("reason", "Reason for invite operation.").String()
+)
+
+func mucCommand(c ejabberd.Client, op string) {
+	room := *mucRoom
+	if op != "list" && room == "" {
+		kingpin.Fatalf("jid of the room is required")
+	}
+
+	switch op {
+	case "list":
+		resp, err := c.OnlineRooms(*mucService)
+		if err != nil {
+			kingpin.Fatalf("muc list error: %s", err)
+		}
+		format(resp)
+	case "create":
+		var err error
+		if len(*mucOptions) == 0 {
+			err = c.CreateRoom(room, *mucHost)
+		} else {
+			err = c.CreateRoomWithOptions(room, *mucHost, mucRoomOptions())
+		}
+		if err != nil {
+			kingpin.Fatalf("muc create error for %s: %s", room, err)
+		}
+	case "destroy":
+		if err := c.DestroyRoom(room); err != nil {
+			kingpin.Fatalf("muc destroy error for %s: %s", room, err)
+		}
+	case "options":
+		resp, err := c.GetRoomOptions(room)
+		if err != nil {
+			kingpin.Fatalf("muc options error for %s: %s", room, err)
+		}
+		format(resp)
+	case "set-option":
+		if len(*mucOptions) == 0 {
+			kingpin.Fatalf("at least one option is required")
+		}
+		for _, opt := range *mucOptions {
+			name, value := splitOption(opt)
+			if err := c.ChangeRoomOption(room, name, value); err != nil {
+				kingpin.Fatalf("muc set-option error for %s: %s", room, err)
+			}
+		}
+	case "occupants":
+		resp, err := c.GetRoomOccupants(room)
+		if err != nil {
+			kingpin.Fatalf("muc occupants error for %s: %s", room, err)
+		}
+		format(resp)
+	case "affiliations":
+		resp, err := c.GetRoomAffiliations(room)
+		if err != nil {
+			kingpin.Fatalf("muc affiliations error for %s: %s", room, err)
+		}
+		format(resp)
+	case "affiliate":
+		if len(*mucJIDs) != 1 {
+			kingpin.Fatalf("exactly one jid is required for affiliate")
+		}
+		if err := c.SetRoomAffiliation(room, (*mucJIDs)[0], *mucAffiliation); err != nil {
+			kingpin.Fatalf("muc affiliate error for %s: %s", room, err)
+		}
+	case "invite":
+		if err := c.SendDirectInvitation(room, *mucPassword, *mucReason, *mucJIDs); err != nil {
+			kingpin.Fatalf("muc invite error for %s: %s", room, err)
+		}
+	}
+}
+
+// mucRoomOptions parses room options given on command line.
+func mucRoomOptions() ejabberd.RoomOptions {
+	var options ejabberd.RoomOptions
+	for _, opt := range *mucOptions {
+		name, value := splitOption(opt)
+		if err := options.Set(name, value); err != nil {
+			kingpin.Fatalf("%s", err)
+		}
+	}
+	return options
+}
+
+// splitOption splits a name=value command line option.
+func splitOption(opt string) (string, string) {
+	parts := strings.SplitN(opt, "=", 2)
+	if len(parts) != 2 || parts[0] == "" {
+		kingpin.Fatalf("invalid option %q, expected name=value", opt)
+	}
+	return parts[0], parts[1]
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"github.com/alecthomas/kingpin/v2"
+	"github.com/processone/ejabberd-api"
+)
+
+var (
+	// ========= roster =========
+	roster             = app.Command("roster", "Operations to perform on user rosters.")
+	rosterOperation    = roster.Arg("operation", "Operation").Required().Enum("list", "add", "delete", "push", "push-alltoall")
+	rosterJID          = roster.Flag("jid", "JID of the roster owner, if different from token owner.").Short('j').String()
+	rosterContact      = roster.Flag("contact", "JID of the contact to add or delete.").Short('c').String()
+	rosterNick         = roster.Flag("nick", "Nickname of the contact to add.").String()
+	rosterGroups       = roster.Flag("group", "Group of the contact to add. Can be repeated.").Short('g').Strings()
+	rosterSubscription = roster.Flag("subscription", "Subscription of the contact to add.").Default("both").Enum("none", "from", "to", "both")
+	rosterFile         = roster.Flag("roster-file", "Roster file on the server node, for push operation.").String()
+	rosterHost         = roster.Flag("host", "Virtual host for push-alltoall operation.").String()
+)
+
+func rosterCommand(c ejabberd.Client, op string) {
+	jid := *rosterJID
+	if jid == "" {
+		jid = c.Token.JID
+	}
+
+	switch op {
+	case "list":
+		resp, err := c.GetRoster(jid)
+		if err != nil {
+			kingpin.Fatalf("roster error for %s: %s", jid, err)
+		}
+		format(resp)
+	case "add":
+		item := ejabberd.RosterItem{
+			JID:          *rosterContact,
+			Nick:         *rosterNick,
+			Subscription: *rosterSubscription,
+			Groups:       *rosterGroups,
+		}
+		if err := c.AddRosterItem(jid, item); err != nil {
+			kingpin.Fatalf("roster add error for %s: %s", jid, err)
+		}
+	case "delete":
+		if err := c.DeleteRosterItem(jid, *rosterContact); err != nil {
+			kingpin.Fatalf("roster delete error for %s: %s", jid, err)
+		}
+	case "push":
+		if err := c.PushRoster(jid, *rosterFile); err != nil {
+			kingpin.Fatalf("roster push error for %s: %s", jid, err)
+		}
+	case "push-alltoall":
+		if len(*rosterGroups) != 1 {
+			kingpin.Fatalf("exactly one group is required for push-alltoall")
+		}
+		if err := c.PushAllToAll(*rosterHost, (*rosterGroups)[0]); err != nil {
+			kingpin.Fatalf("roster push-alltoall error for %s: %s", *rosterHost, err)
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"github.com/alecthomas/kingpin/v2"
+	"github.com/processone/ejabberd-api"
+)
+
+var (
+	// ========= send =========
+	send               = app.Command("send", "Send messages and stanzas on behalf of a JID.")
+	sendMessage        = send.Command("message", "Send a message.")
+	sendMessageFrom    = sendMessage.Flag("from", "JID of the sender, if different from token owner. Can be a server JID.").String()
+	sendMessageTo      = sendMessage.Flag("to", "JID of the recipient.").Required().String()
+	sendMessageType    = sendMessage.Flag("type", "Message type.").Default("normal").Enum("chat", "normal", "headline")
+	sendMessageSubject = sendMessage.Flag("subject", "Message subject.").String()
+	sendMessageBody    = sendMessage.Flag("body", "Message body.").Required().String()
+	sendStanza         = send.Command("stanza", "Send a raw XML stanza.")
+	sendStanzaFrom     = sendStanza.Flag("from", "JID of the sender, if different from token owner.").String()
+	sendStanzaTo       = sendStanza.Flag("to", "JID of the recipient.").Required().String()
+	sendStanzaXML      = sendStanza.Arg("stanza", "XML stanza to send.").Required().String()
+)
+
+func sendMessageCommand(c ejabberd.Client) {
+	m := ejabberd.OutgoingMessage{
+		Type:    *sendMessageType,
+		From:    *sendMessageFrom,
+		To:      *sendMessageTo,
+		Subject: *sendMessageSubject,
+		Body:    *sendMessageBody,
+	}
+	if m.From == "" {
+		m.From = c.Token.JID
+	}
+
+	if err := c.SendMessage(m); err != nil {
+		kingpin.Fatalf("send message error to %s: %s", m.To, err)
+	}
+}
+
+func sendStanzaCommand(c ejabberd.Client) {
+	from := *sendStanzaFrom
+	if from == "" {
+		from = c.Token.JID
+	}
+
+	if err := c.SendStanza(from, *sendStanzaTo, *sendStanzaXML); err != nil {
+		kingpin.Fatalf("send stanza error to %s: %s", *sendStanzaTo, err)
+	}
+}
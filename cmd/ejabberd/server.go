@@ -0,0 +1,84 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/alecthomas/kingpin/v2"
+	"github.com/processone/ejabberd-api"
+)
+
+var (
+	// ========= server =========
+	server             = app.Command("server", "Server status and node lifecycle operations.")
+	serverOperation    = server.Arg("operation", "Operation").Required().Enum("status", "stop", "restart", "stop-kindly", "reopen-log", "reload-config", "loglevel", "set-loglevel")
+	serverLevel        = server.Flag("level", "Log level for set-loglevel operation.").Enum(ejabberd.LogLevels...)
+	serverDelay        = server.Flag("delay", "Delay before stopping, for stop-kindly operation.").Default("60s").Duration()
+	serverAnnouncement = server.Flag("announcement", "Announcement sent to connected users, for stop-kindly operation.").Default("The server will stop shortly.").String()
+	serverYes          = server.Flag("yes", "Do not ask for confirmation before stopping or restarting the node.").Short('y').Bool()
+)
+
+func serverCommand(c ejabberd.Client, op string) {
+	switch op {
+	case "status":
+		resp, err := c.Status()
+		if err != nil {
+			kingpin.Fatalf("status error: %s", err)
+		}
+		format(resp)
+	case "stop":
+		confirm(*serverYes, "Stop ejabberd node at %s?", c.BaseURL)
+		if err := c.Stop(); err != nil {
+			kingpin.Fatalf("stop error: %s", err)
+		}
+	case "restart":
+		confirm(*serverYes, "Restart ejabberd node at %s?", c.BaseURL)
+		if err := c.Restart(); err != nil {
+			kingpin.Fatalf("restart error: %s", err)
+		}
+	case "stop-kindly":
+		confirm(*serverYes, "Stop ejabberd node at %s in %s?", c.BaseURL, *serverDelay)
+		if err := c.StopKindly(*serverDelay, *serverAnnouncement); err != nil {
+			kingpin.Fatalf("stop-kindly error: %s", err)
+		}
+	case "reopen-log":
+		if err := c.ReopenLog(); err != nil {
+			kingpin.Fatalf("reopen-log error: %s", err)
+		}
+	case "reload-config":
+		if err := c.ReloadConfig(); err != nil {
+			kingpin.Fatalf("reload-config error: %s", err)
+		}
+	case "loglevel":
+		resp, err := c.GetLogLevel()
+		if err != nil {
+			kingpin.Fatalf("loglevel error: %s", err)
+		}
+		format(resp)
+	case "set-loglevel":
+		if *serverLevel == "" {
+			kingpin.Fatalf("log level is required")
+		}
+		if err := c.SetLogLevel(*serverLevel); err != nil {
+			kingpin.Fatalf("set-loglevel error: %s", err)
+		}
+	}
+}
+
+// confirm asks the user to confirm a disruptive operation, unless yes
+// is set from --yes flag, and exits if not confirmed.
+func confirm(yes bool, question string, args ...interface{}) {
+	if yes {
+		return
+	}
+
+	fmt.Fprintf(os.Stderr, question+" [y/N] ", args...)
+	answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return
+	}
+	kingpin.Fatalf("operation canceled")
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"github.com/alecthomas/kingpin/v2"
+	"github.com/processone/ejabberd-api"
+)
+
+var (
+	// ========= srg =========
+	srg            = app.Command("srg", "Operations to perform on shared roster groups.")
+	srgOperation   = srg.Arg("operation", "Operation").Required().Enum("list", "create", "delete", "info", "members", "add-user", "del-user")
+	srgGroup       = srg.Flag("group", "Identifier of the group.").Short('g').String()
+	srgHost        = srg.Flag("host", "Virtual host of the group. Defaults to token owner domain.").String()
+	srgLabel       = srg.Flag("label", "Label of the group to create. Defaults to group identifier.").String()
+	srgDescription = srg.Flag("description", "Description of the group to create.").String()
+	srgDisplay     = srg.Flag("display", "Group displayed to members of the group to create. Can be repeated.").Strings()
+	srgJID         = srg.Flag("jid", "JID of the user to add or delete.").Short('j').String()
+)
+
+func srgCommand(c ejabberd.Client, op string) {
+	host := *srgHost
+	if host == "" {
+		host = c.Token.Domain()
+	}
+
+	group := *srgGroup
+	if op != "list" && group == "" {
+		kingpin.Fatalf("group identifier is required")
+	}
+
+	switch op {
+	case "list":
+		resp, err := c.ListSRG(host)
+		if err != nil {
+			kingpin.Fatalf("srg list error for %s: %s", host, err)
+		}
+		format(resp)
+	case "create":
+		info := ejabberd.SRGInfo{
+			Name:            group,
+			Host:            host,
+			Label:           *srgLabel,
+			Description:     *srgDescription,
+			DisplayedGroups: *srgDisplay,
+		}
+		if err := c.CreateSRG(info); err != nil {
+			kingpin.Fatalf("srg create error for %s: %s", group, err)
+		}
+	case "delete":
+		if err := c.DeleteSRG(group, host); err != nil {
+			kingpin.Fatalf("srg delete error for %s: %s", group, err)
+		}
+	case "info":
+		resp, err := c.GetSRGInfo(group, host)
+		if err != nil {
+			kingpin.Fatalf("srg info error for %s: %s", group, err)
+		}
+		format(resp)
+	case "members":
+		resp, err := c.GetSRGMembers(group, host)
+		if err != nil {
+			kingpin.Fatalf("srg members error for %s: %s", group, err)
+		}
+		format(resp)
+	case "add-user":
+		if err := c.AddSRGUser(*srgJID, group, host); err != nil {
+			kingpin.Fatalf("srg add-user error for %s: %s", group, err)
+		}
+	case "del-user":
+		if err := c.DeleteSRGUser(*srgJID, group, host); err != nil {
+			kingpin.Fatalf("srg del-user error for %s: %s", group, err)
+		}
+	}
+}
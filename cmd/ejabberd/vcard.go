@@ -0,0 +1,55 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/alecthomas/kingpin/v2"
+	"github.com/processone/ejabberd-api"
+)
+
+var (
+	// ========= vcard =========
+	vcard          = app.Command("vcard", "Operations to perform on user vCards.")
+	vcardOperation = vcard.Arg("operation", "Operation").Required().Enum("get", "set")
+	vcardJID       = vcard.Flag("jid", "JID of the vCard owner, if different from token owner.").Short('j').String()
+	vcardField     = vcard.Flag("field", "vCard field: "+strings.Join(ejabberd.VCardFieldNames(), ", ")+", or NAME.SUBNAME.").Required().String()
+	vcardValues    = vcard.Flag("value", "Value to set. Can be repeated with --multi.").Strings()
+	vcardMulti     = vcard.Flag("multi", "Get or set all values of a repeated field, like email.").Bool()
+)
+
+func vcardCommand(c ejabberd.Client, op string) {
+	jid := *vcardJID
+	if jid == "" {
+		jid = c.Token.JID
+	}
+
+	field, err := ejabberd.ParseVCardField(*vcardField)
+	if err != nil {
+		kingpin.Fatalf("%s", err)
+	}
+
+	switch op {
+	case "get":
+		var resp ejabberd.Response
+		if *vcardMulti {
+			resp, err = c.GetVCardMulti(jid, field)
+		} else {
+			resp, err = c.GetVCard(jid, field)
+		}
+		if err != nil {
+			kingpin.Fatalf("vcard get error for %s: %s", jid, err)
+		}
+		format(resp)
+	case "set":
+		if *vcardMulti {
+			err = c.SetVCardMulti(jid, field, *vcardValues)
+		} else if len(*vcardValues) != 1 {
+			kingpin.Fatalf("exactly one value is required, use --multi to set several values")
+		} else {
+			err = c.SetVCard(jid, field, (*vcardValues)[0])
+		}
+		if err != nil {
+			kingpin.Fatalf("vcard set error for %s: %s", jid, err)
+		}
+	}
+}
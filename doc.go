@@ -67,6 +67,20 @@ ejabberd available command.
 Check 'ejabberd.Client' documentation for details on the currently
 available commands.
 
+API versions
+
+ejabberd commands are versioned, and some of them changed arguments
+or behaviour between API versions. Each typed command of 'ejabberd.Client'
+calls the versioned path of the command it has been written for,
+'api/<command>/v<N>', whatever the server default version is:
+
+    v2   ban_account, unban_account, get_ban_details (ejabberd 24.06+)
+    v1   all other typed commands
+
+Generic calls with 'Client.CallRaw' and 'Client.CallStream' use
+'Client.APIVersion' instead, or the server default version when it is
+not set.
+
 OAuth Token file format
 
 As a default, the token is stored in a file called
@@ -0,0 +1,213 @@
+package ejabberd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Error kinds returned by the client. Errors returned by Client
+// methods can be matched against them with errors.Is, for example:
+//
+//	if errors.Is(err, ejabberd.ErrConflict) {
+//		// User already exists
+//	}
+var (
+	// ErrNotFound is matched by errors on unknown commands, endpoints
+	// or entities.
+	ErrNotFound = errors.New("not found")
+	// ErrUnauthorized is matched when the token is missing, invalid
+	// or expired, or when credentials are rejected.
+	ErrUnauthorized = errors.New("unauthorized")
+	// ErrForbidden is matched when the token does not grant access
+	// to the command, for example because of an insufficient scope.
+	ErrForbidden = errors.New("forbidden")
+	// ErrConflict is matched when the command conflicts with server
+	// state, for example when registering an existing user.
+	ErrConflict = errors.New("conflict")
+	// ErrTransport is matched when the request could not be sent or
+	// the response could not be read.
+	ErrTransport = errors.New("transport error")
+	// ErrDecode is matched when the server response cannot be
+	// decoded.
+	ErrDecode = errors.New("cannot decode response")
+)
+
+// errorKind classifies errors for metrics and tracing: api, decode,
+// transport, canceled, too_large or other.
+func errorKind(err error) string {
+	var apiError APIError
+	var tooLarge ResponseTooLargeError
+	switch {
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return "canceled"
+	case errors.As(err, &apiError):
+		return "api"
+	case errors.As(err, &tooLarge):
+		return "too_large"
+	case errors.Is(err, ErrTransport):
+		return "transport"
+	case errors.Is(err, ErrDecode):
+		return "decode"
+	}
+	return "other"
+}
+
+//==============================================================================
+
+// APIError represents ejabberd error returned by the server as result
+// of ejabberd API calls.
+type APIError struct {
+	Status  string `json:"status"`
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+
+	// HTTP status code of the response, name of the command and raw
+	// response body.
+	HTTPStatus int    `json:"http_status,omitempty"`
+	Command    string `json:"command,omitempty"`
+	Body       []byte `json:"-"`
+}
+
+// parseError builds APIError from ejabberd response to command name
+// with HTTP status code. Responses that are not JSON, for example
+// from a proxy, are reported with the response body as message.
+func parseError(name string, code int, body []byte) APIError {
+	var resp APIError
+	if err := json.Unmarshal(body, &resp); err != nil || resp.Message == "" {
+		resp.Status = "error"
+		resp.Message = strings.TrimSpace(string(body))
+		if resp.Message == "" {
+			resp.Message = http.StatusText(code)
+		}
+	}
+	resp.HTTPStatus = code
+	resp.Command = name
+	resp.Body = body
+	return resp
+}
+
+// JSON represents ejabberd error response as a JSON string, for further
+// processing with other tools.
+func (e APIError) JSON() string {
+	body, _ := json.Marshal(e)
+	return string(body)
+}
+
+func (e APIError) Error() string {
+	return fmt.Sprintf("Error %d: %s", e.Code, e.Message)
+}
+
+// Is reports whether the error matches one of the error kinds, based
+// on the HTTP status code returned by the server.
+func (e APIError) Is(target error) bool {
+	switch e.HTTPStatus {
+	case http.StatusNotFound:
+		return target == ErrNotFound
+	case http.StatusUnauthorized:
+		return target == ErrUnauthorized
+	case http.StatusForbidden:
+		return target == ErrForbidden
+	case http.StatusConflict:
+		return target == ErrConflict
+	}
+	return false
+}
+
+//==============================================================================
+
+// TransportError is returned when the HTTP request to ejabberd fails,
+// for example because the server cannot be reached.
+type TransportError struct {
+	Command string
+	Err     error
+}
+
+func (e TransportError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Command, e.Err)
+}
+
+// Unwrap returns the underlying network error.
+func (e TransportError) Unwrap() error {
+	return e.Err
+}
+
+// Is reports whether target is ErrTransport.
+func (e TransportError) Is(target error) bool {
+	return target == ErrTransport
+}
+
+//==============================================================================
+
+// DecodeError is returned when ejabberd response to a command cannot
+// be decoded.
+type DecodeError struct {
+	Command string
+	Body    []byte
+	Err     error
+}
+
+func (e DecodeError) Error() string {
+	return fmt.Sprintf("%s: cannot decode response: %s", e.Command, e.Err)
+}
+
+// Unwrap returns the underlying decoding error.
+func (e DecodeError) Unwrap() error {
+	return e.Err
+}
+
+// Is reports whether target is ErrDecode.
+func (e DecodeError) Is(target error) bool {
+	return target == ErrDecode
+}
+
+//==============================================================================
+
+// ResponseTooLargeError is returned when ejabberd response to a
+// command is bigger than the client maximum response size. Use
+// CallStream to process large responses.
+type ResponseTooLargeError struct {
+	Command string
+	Limit   int64
+}
+
+func (e ResponseTooLargeError) Error() string {
+	return fmt.Sprintf("response to %s exceeds %d bytes", e.Command, e.Limit)
+}
+
+//==============================================================================
+
+// TokenError is returned when ejabberd refuses to deliver an OAuth
+// token.
+type TokenError struct {
+	HTTPStatus  int
+	Code        string // OAuth error code, like invalid_grant
+	Description string
+}
+
+func (e TokenError) Error() string {
+	if e.HTTPStatus == http.StatusNotFound {
+		return "oauth endpoint not found (404)"
+	}
+	if e.Description == "" {
+		return "bad request"
+	}
+	return e.Description
+}
+
+// Is reports whether the error matches one of the error kinds, based
+// on HTTP status code and OAuth error code.
+func (e TokenError) Is(target error) bool {
+	switch {
+	case e.HTTPStatus == http.StatusNotFound:
+		return target == ErrNotFound
+	case e.Code == "invalid_grant", e.Code == "invalid_client", e.HTTPStatus == http.StatusUnauthorized:
+		return target == ErrUnauthorized
+	case e.Code == "invalid_scope", e.Code == "unauthorized_client", e.HTTPStatus == http.StatusForbidden:
+		return target == ErrForbidden
+	}
+	return false
+}
@@ -0,0 +1,54 @@
+package ejabberd_test
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/processone/ejabberd-api"
+)
+
+func Test_Errors(t *testing.T) {
+	var tests = []struct {
+		code int
+		body string
+		want error
+	}{
+		{404, `{"status":"error","code":1,"message":"Unknown command"}`, ejabberd.ErrNotFound},
+		{401, `{"status":"error","code":1,"message":"OAuth token not found"}`, ejabberd.ErrUnauthorized},
+		{403, `{"status":"error","code":32,"message":"Insufficient scope"}`, ejabberd.ErrForbidden},
+		{409, `{"status":"error","code":10090,"message":"User already registered"}`, ejabberd.ErrConflict},
+		{200, `{"unexpected"`, ejabberd.ErrDecode},
+	}
+	for _, test := range tests {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(test.code)
+			fmt.Fprintln(w, test.body)
+		}))
+
+		client := ejabberd.Client{BaseURL: server.URL}
+		_, err := client.RegisterUser("test@localhost", "passw0rd")
+		if !errors.Is(err, test.want) {
+			t.Errorf("RegisterUser with HTTP %d: error %v does not match %v", test.code, err, test.want)
+		}
+
+		var apiError ejabberd.APIError
+		if test.code != 200 && (!errors.As(err, &apiError) || apiError.HTTPStatus != test.code || apiError.Command != "register") {
+			t.Errorf("RegisterUser with HTTP %d: incorrect APIError %#v", test.code, apiError)
+		}
+		server.Close()
+	}
+}
+
+func Test_TransportError(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	server.Close()
+
+	client := ejabberd.Client{BaseURL: server.URL}
+	_, err := client.Stats("registeredusers")
+	if !errors.Is(err, ejabberd.ErrTransport) {
+		t.Errorf("Stats error %v does not match %v", err, ejabberd.ErrTransport)
+	}
+}
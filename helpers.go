@@ -1,6 +1,9 @@
 package ejabberd
 
-import "strings"
+import (
+	"strings"
+	"time"
+)
 
 // prepareScope ensures we return scopes as space separated. However,
 // we accept comma separated scopes as input as well for convenience.
@@ -21,3 +24,13 @@ func stringInSlice(a string, list []string) bool {
 	}
 	return false
 }
+
+// parseTimestamp parses timestamps returned by ejabberd, in RFC 3339
+// format. It returns zero time if the value cannot be parsed.
+func parseTimestamp(s string) time.Time {
+	t, err := time.Parse(time.RFC3339Nano, s)
+	if err != nil {
+		return time.Time{}
+	}
+	return t
+}
@@ -29,3 +29,27 @@ func TestJoinURL(t *testing.T) {
 		}
 	}
 }
+
+func TestAPIURL(t *testing.T) {
+	var tests = []struct {
+		baseURL string
+		apiPath string
+		name    string
+		version int
+		want    string
+	}{
+		{"http://localhost:5281", "", "stats", 0, "http://localhost:5281/api/stats"},
+		{"http://localhost:5281/", "api/", "stats", 1, "http://localhost:5281/api/stats/v1"},
+		{"http://localhost:5281", "/rest", "register", 2, "http://localhost:5281/rest/register/v2"},
+	}
+	for _, test := range tests {
+		got, err := apiURL(test.baseURL, test.apiPath, test.name, test.version)
+		if err != nil {
+			t.Errorf("error on apiURL(%q, %q, %q, %d): %s", test.baseURL, test.apiPath, test.name, test.version, err)
+			continue
+		}
+		if got != test.want {
+			t.Errorf("apiURL(%q, %q, %q, %d) = %q, want %q", test.baseURL, test.apiPath, test.name, test.version, got, test.want)
+		}
+	}
+}
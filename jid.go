@@ -36,3 +36,21 @@ func parseJID(sjid string) (jid, error) {
 func (j jid) bare() string {
 	return fmt.Sprintf("%s@%s", j.username, j.domain)
 }
+
+// checkJID validates a bare or full JID, like parseJID does, but also
+// accepts server JIDs without username, like localhost.
+func checkJID(sjid string) error {
+	s1 := strings.SplitN(sjid, "/", 2)
+	if len(s1) > 1 && s1[1] == "" {
+		return fmt.Errorf("invalid jid: %s", sjid)
+	}
+
+	s2 := strings.Split(s1[0], "@")
+	switch {
+	case len(s2) == 1 && s2[0] != "":
+		return nil
+	case len(s2) == 2 && s2[0] != "" && s2[1] != "":
+		return nil
+	}
+	return fmt.Errorf("invalid jid: %s", sjid)
+}
@@ -25,3 +25,42 @@ func Test_JID(t *testing.T) {
 		}
 	}
 }
+
+func Test_CheckJID(t *testing.T) {
+	var tests = []struct {
+		input string
+		valid bool
+	}{
+		{"username@domain/resource", true},
+		{"username@domain", true},
+		{"domain", true},
+		{"domain/resource", true},
+		{"", false},
+		{"@domain", false},
+		{"username@", false},
+		{"username@domain/", false},
+		{"user@name@domain", false},
+	}
+	for _, test := range tests {
+		if err := checkJID(test.input); (err == nil) != test.valid {
+			t.Errorf("checkJID(%q) = %v", test.input, err)
+		}
+	}
+}
+
+func Test_TokenDomain(t *testing.T) {
+	var tests = []struct {
+		jid  string
+		want string
+	}{
+		{"admin@localhost", "localhost"},
+		{"admin@localhost/resource", "localhost"},
+		{"localhost", ""},
+		{"", ""},
+	}
+	for _, test := range tests {
+		if got := (OAuthToken{JID: test.jid}).Domain(); got != test.want {
+			t.Errorf("Domain() for %q = %q, want %q", test.jid, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package ejabberd_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/processone/ejabberd-api"
+)
+
+func Test_LimiterConcurrency(t *testing.T) {
+	var inFlight, maxInFlight int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&inFlight, 1)
+		defer atomic.AddInt32(&inFlight, -1)
+		for {
+			m := atomic.LoadInt32(&maxInFlight)
+			if n <= m || atomic.CompareAndSwapInt32(&maxInFlight, m, n) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+		fmt.Fprintln(w, `{"stat": 1}`)
+	}))
+	defer server.Close()
+
+	client := ejabberd.Client{BaseURL: server.URL, Limiter: ejabberd.NewLimiter(0, 0, 2)}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := client.Stats("onlineusers"); err != nil {
+				t.Errorf("Stats failed: %s", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if maxInFlight > 2 {
+		t.Errorf("Too many concurrent requests %d > 2", maxInFlight)
+	}
+}
+
+func Test_LimiterRate(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, `{"stat": 1}`)
+	}))
+	defer server.Close()
+
+	client := ejabberd.Client{BaseURL: server.URL, Limiter: ejabberd.NewLimiter(1, 1, 0)}
+	if _, err := client.Stats("onlineusers"); err != nil {
+		t.Fatalf("Stats failed: %s", err)
+	}
+
+	// Bucket is empty: next call has to wait for about a second.
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := client.StatsContext(ctx, "onlineusers"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("StatsContext error = %v, want %v", err, context.DeadlineExceeded)
+	}
+}
@@ -0,0 +1,150 @@
+package ejabberd
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// redacted replaces secrets in logged values.
+const redacted = "[REDACTED]"
+
+// logMiddleware logs each API call attempt on logger. Successful calls
+// are logged at debug level and failed calls at warning level. Bearer
+// token and passwords in request bodies are redacted.
+func (c Client) logMiddleware(logger *slog.Logger) Middleware {
+	return func(next CallHandler) CallHandler {
+		return func(ctx context.Context, call *APICall) (*APIResult, error) {
+			u, _ := apiURL(c.BaseURL, c.APIPath, call.Command, call.Version)
+			attrs := []slog.Attr{
+				slog.String("command", call.Command),
+				slog.String("method", call.Method),
+				slog.String("url", u),
+				slog.Bool("admin", call.Admin),
+				slog.Int("attempt", call.Attempt),
+				slog.Any("header", redactHeader(call.Header)),
+			}
+			if len(call.Query) > 0 {
+				attrs = append(attrs, slog.String("query", call.Query.Encode()))
+			}
+			if len(call.Body) > 0 {
+				attrs = append(attrs, slog.String("body", redactBody(call.Body)))
+			}
+
+			start := time.Now()
+			res, err := next(ctx, call)
+
+			level := slog.LevelDebug
+			attrs = append(attrs, slog.Duration("latency", time.Since(start)))
+			if res != nil {
+				attrs = append(attrs, slog.Int("status", res.StatusCode))
+				if !res.Streamed {
+					attrs = append(attrs, slog.Int("size", len(res.Body)))
+				}
+				if res.StatusCode != 200 {
+					level = slog.LevelWarn
+				}
+			}
+			if err != nil {
+				level = slog.LevelWarn
+				attrs = append(attrs, slog.String("error", err.Error()))
+			}
+			logger.LogAttrs(ctx, level, "ejabberd api call", attrs...)
+			return res, err
+		}
+	}
+}
+
+// logToken logs a token request. Password is never logged.
+func logToken(ctx context.Context, logger *slog.Logger, url string, j jid, scope string, latency time.Duration, err error) {
+	attrs := []slog.Attr{
+		slog.String("url", url),
+		slog.String("jid", j.bare()),
+		slog.String("scope", prepareScope(scope)),
+		slog.Duration("latency", latency),
+	}
+	level := slog.LevelDebug
+	if err != nil {
+		level = slog.LevelWarn
+		attrs = append(attrs, slog.String("error", err.Error()))
+	}
+	logger.LogAttrs(ctx, level, "ejabberd token request", attrs...)
+}
+
+// redactHeader returns a copy of header with the bearer token hidden.
+func redactHeader(header http.Header) http.Header {
+	h := header.Clone()
+	if h.Get("Authorization") != "" {
+		h.Set("Authorization", "Bearer "+redacted)
+	}
+	return h
+}
+
+// redactBody hides password values in a JSON request body. Bodies that
+// are not JSON objects are not logged at all, as they may contain
+// secrets we cannot identify.
+func redactBody(body []byte) string {
+	var data interface{}
+	if err := json.Unmarshal(body, &data); err != nil {
+		return redacted
+	}
+	b, err := json.Marshal(redactValue(data))
+	if err != nil {
+		return redacted
+	}
+	return string(b)
+}
+
+func redactValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		// Room options are set as option / value in change_room_option
+		// and as a list of name / value pairs in create_room_with_opts.
+		if option, ok := v["option"].(string); ok && isSecretKey(option) {
+			if _, ok := v["value"]; ok {
+				v["value"] = redacted
+			}
+		}
+		if options, ok := v["options"].([]interface{}); ok {
+			redactOptions(options)
+		}
+		for key, value := range v {
+			if isSecretKey(key) {
+				v[key] = redacted
+			} else {
+				v[key] = redactValue(value)
+			}
+		}
+	case []interface{}:
+		for i, value := range v {
+			v[i] = redactValue(value)
+		}
+	}
+	return v
+}
+
+// redactOptions hides secret values in a list of name / value option
+// pairs.
+func redactOptions(options []interface{}) {
+	for _, opt := range options {
+		pair, ok := opt.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if name, ok := pair["name"].(string); ok && isSecretKey(name) {
+			if _, ok := pair["value"]; ok {
+				pair["value"] = redacted
+			}
+		}
+	}
+}
+
+// isSecretKey tells if a JSON key holds a secret, like password in
+// register or newpass in change_password.
+func isSecretKey(key string) bool {
+	key = strings.ToLower(key)
+	return strings.Contains(key, "pass") || strings.Contains(key, "secret") || strings.Contains(key, "token")
+}
@@ -0,0 +1,82 @@
+package ejabberd
+
+import (
+	"bytes"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRedactBody(t *testing.T) {
+	var tests = []struct {
+		input string
+		want  string
+	}{
+		{`{"user":"test","host":"localhost","password":"s3cret"}`, `{"host":"localhost","password":"[REDACTED]","user":"test"}`},
+		{`{"user":"test","newpass":"s3cret"}`, `{"newpass":"[REDACTED]","user":"test"}`},
+		{`[{"passwordhash":"abcd"}]`, `[{"passwordhash":"[REDACTED]"}]`},
+		{`{"name":"team","options":[{"name":"password","value":"s3cret"},{"name":"title","value":"Team"}]}`, `{"name":"team","options":[{"name":"password","value":"[REDACTED]"},{"name":"title","value":"Team"}]}`},
+		{`{"name":"team","service":"conference.localhost","option":"password","value":"s3cret"}`, `{"name":"team","option":"password","service":"conference.localhost","value":"[REDACTED]"}`},
+		{`{"name":"passwords","service":"conference.localhost","option":"title","value":"Vault"}`, `{"name":"passwords","option":"title","service":"conference.localhost","value":"Vault"}`},
+		{`{"name":"password","value":"kept"}`, `{"name":"password","value":"kept"}`},
+		{`not json`, `[REDACTED]`},
+	}
+	for _, test := range tests {
+		if got := redactBody([]byte(test.input)); got != test.want {
+			t.Errorf("redactBody(%q) = %q, want %q", test.input, got, test.want)
+		}
+	}
+}
+
+func TestLogger(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `"Success"`)
+	}))
+	defer server.Close()
+
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	c := Client{BaseURL: server.URL, Token: OAuthToken{AccessToken: "t0ken"}, Logger: logger}
+	if _, err := c.RegisterUser("test@localhost", "s3cret"); err != nil {
+		t.Fatalf("RegisterUser failed: %s", err)
+	}
+
+	out := buf.String()
+	for _, secret := range []string{"t0ken", "s3cret"} {
+		if strings.Contains(out, secret) {
+			t.Errorf("Log contains secret %q: %s", secret, out)
+		}
+	}
+	for _, want := range []string{"command=register", "admin=true", "status=200", "size=9"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Log does not contain %q: %s", want, out)
+		}
+	}
+}
+
+func TestLoggerRoomPassword(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `0`)
+	}))
+	defer server.Close()
+
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	c := Client{BaseURL: server.URL, Logger: logger}
+	if err := c.ChangeRoomOption("team@conference.localhost", "password", "s3cret"); err != nil {
+		t.Fatalf("ChangeRoomOption failed: %s", err)
+	}
+
+	out := buf.String()
+	if strings.Contains(out, "s3cret") {
+		t.Errorf("Log contains room password: %s", out)
+	}
+	for _, want := range []string{"command=change_room_option", `\"option\":\"password\"`} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Log does not contain %q: %s", want, out)
+		}
+	}
+}
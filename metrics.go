@@ -0,0 +1,228 @@
+package ejabberd
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultLatencyBuckets are the upper bounds, in seconds, of the
+// latency histogram buckets used when none are given to NewMetrics.
+var DefaultLatencyBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// Metrics collects statistics about ejabberd API calls and exposes
+// them in Prometheus text exposition format. It is plugged in a
+// client as a middleware:
+//
+//	metrics := ejabberd.NewMetrics()
+//	client.Middleware = append(client.Middleware, metrics.Middleware())
+//	http.Handle("/metrics", metrics)
+//
+// Metrics does not depend on Prometheus client library. Applications
+// already using it can write their own Middleware instead.
+//
+// Exposed metrics, labelled by command, are:
+//
+//	ejabberd_api_requests_total             Number of requests sent.
+//	ejabberd_api_errors_total               Number of failed requests, by type and status.
+//	ejabberd_api_request_duration_seconds   Histogram of request latencies, failed requests included.
+//	ejabberd_api_in_flight_requests         Number of requests in progress.
+//
+// Error type is one of api (HTTP status other than 200), transport,
+// canceled or too_large. Each retry attempt counts as a request.
+type Metrics struct {
+	buckets []float64
+
+	mu       sync.Mutex
+	commands map[string]*commandMetrics
+}
+
+type errorKey struct {
+	kind   string
+	status int
+}
+
+type commandMetrics struct {
+	requests uint64
+	inFlight int64
+	errors   map[errorKey]uint64
+
+	// Latency histogram: cumulative counts per bucket, sum and count.
+	counts []uint64
+	sum    float64
+	count  uint64
+}
+
+// NewMetrics returns a Metrics collector. Latency histogram uses the
+// given buckets upper bounds in seconds, or DefaultLatencyBuckets.
+func NewMetrics(buckets ...float64) *Metrics {
+	if len(buckets) == 0 {
+		buckets = DefaultLatencyBuckets
+	}
+	b := append([]float64(nil), buckets...)
+	sort.Float64s(b)
+	return &Metrics{buckets: b, commands: make(map[string]*commandMetrics)}
+}
+
+// Middleware returns a middleware recording API calls in m.
+func (m *Metrics) Middleware() Middleware {
+	return func(next CallHandler) CallHandler {
+		return func(ctx context.Context, call *APICall) (*APIResult, error) {
+			m.start(call.Command)
+			start := time.Now()
+			res, err := next(ctx, call)
+			m.done(call.Command, time.Since(start), res, err)
+			return res, err
+		}
+	}
+}
+
+// get returns metrics for command. m.mu must be held.
+func (m *Metrics) get(command string) *commandMetrics {
+	cm, ok := m.commands[command]
+	if !ok {
+		cm = &commandMetrics{
+			errors: make(map[errorKey]uint64),
+			counts: make([]uint64, len(m.buckets)),
+		}
+		m.commands[command] = cm
+	}
+	return cm
+}
+
+func (m *Metrics) start(command string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	cm := m.get(command)
+	cm.requests++
+	cm.inFlight++
+}
+
+// done records the end of a call to command, which took latency,
+// whether it succeeded or not.
+func (m *Metrics) done(command string, latency time.Duration, res *APIResult, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	cm := m.get(command)
+	cm.inFlight--
+
+	seconds := latency.Seconds()
+	for i, bound := range m.buckets {
+		if seconds <= bound {
+			cm.counts[i]++
+		}
+	}
+	cm.sum += seconds
+	cm.count++
+
+	switch {
+	case err != nil:
+		status := 0
+		if res != nil {
+			status = res.StatusCode
+		}
+		cm.errors[errorKey{kind: errorKind(err), status: status}]++
+	case res != nil && res.StatusCode != 200:
+		cm.errors[errorKey{kind: "api", status: res.StatusCode}]++
+	}
+}
+
+// ServeHTTP writes metrics in Prometheus text exposition format.
+func (m *Metrics) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	_, _ = m.WriteTo(w)
+}
+
+// WriteTo writes metrics to w in Prometheus text exposition format.
+func (m *Metrics) WriteTo(w io.Writer) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	names := make([]string, 0, len(m.commands))
+	for name := range m.commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	cw := &countWriter{w: bufio.NewWriter(w)}
+
+	cw.printf("# HELP ejabberd_api_requests_total Number of ejabberd API requests.\n")
+	cw.printf("# TYPE ejabberd_api_requests_total counter\n")
+	for _, name := range names {
+		cw.printf("ejabberd_api_requests_total{command=%s} %d\n", quoteLabel(name), m.commands[name].requests)
+	}
+
+	cw.printf("# HELP ejabberd_api_errors_total Number of failed ejabberd API requests.\n")
+	cw.printf("# TYPE ejabberd_api_errors_total counter\n")
+	for _, name := range names {
+		cm := m.commands[name]
+		keys := make([]errorKey, 0, len(cm.errors))
+		for k := range cm.errors {
+			keys = append(keys, k)
+		}
+		sort.Slice(keys, func(i, j int) bool {
+			if keys[i].kind != keys[j].kind {
+				return keys[i].kind < keys[j].kind
+			}
+			return keys[i].status < keys[j].status
+		})
+		for _, k := range keys {
+			cw.printf("ejabberd_api_errors_total{command=%s,type=%s,status=\"%d\"} %d\n",
+				quoteLabel(name), quoteLabel(k.kind), k.status, cm.errors[k])
+		}
+	}
+
+	cw.printf("# HELP ejabberd_api_request_duration_seconds Latency of ejabberd API requests.\n")
+	cw.printf("# TYPE ejabberd_api_request_duration_seconds histogram\n")
+	for _, name := range names {
+		cm := m.commands[name]
+		for i, bound := range m.buckets {
+			cw.printf("ejabberd_api_request_duration_seconds_bucket{command=%s,le=\"%s\"} %d\n",
+				quoteLabel(name), strconv.FormatFloat(bound, 'g', -1, 64), cm.counts[i])
+		}
+		cw.printf("ejabberd_api_request_duration_seconds_bucket{command=%s,le=\"+Inf\"} %d\n", quoteLabel(name), cm.count)
+		cw.printf("ejabberd_api_request_duration_seconds_sum{command=%s} %s\n", quoteLabel(name), strconv.FormatFloat(cm.sum, 'g', -1, 64))
+		cw.printf("ejabberd_api_request_duration_seconds_count{command=%s} %d\n", quoteLabel(name), cm.count)
+	}
+
+	cw.printf("# HELP ejabberd_api_in_flight_requests Number of ejabberd API requests in progress.\n")
+	cw.printf("# TYPE ejabberd_api_in_flight_requests gauge\n")
+	for _, name := range names {
+		cw.printf("ejabberd_api_in_flight_requests{command=%s} %d\n", quoteLabel(name), m.commands[name].inFlight)
+	}
+
+	if cw.err == nil {
+		cw.err = cw.w.Flush()
+	}
+	return cw.n, cw.err
+}
+
+// quoteLabel quotes a label value, escaping backslash, double quote
+// and line feed as required by exposition format.
+func quoteLabel(s string) string {
+	s = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s)
+	return `"` + s + `"`
+}
+
+// countWriter counts written bytes and keeps the first write error.
+type countWriter struct {
+	w   *bufio.Writer
+	n   int64
+	err error
+}
+
+func (cw *countWriter) printf(format string, args ...interface{}) {
+	if cw.err != nil {
+		return
+	}
+	n, err := fmt.Fprintf(cw.w, format, args...)
+	cw.n += int64(n)
+	cw.err = err
+}
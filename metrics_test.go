@@ -0,0 +1,81 @@
+package ejabberd_test
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/processone/ejabberd-api"
+)
+
+func Test_Metrics(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/register/v1" {
+			w.WriteHeader(409)
+			fmt.Fprint(w, `{"status":"error","code":10090,"message":"User already registered"}`)
+			return
+		}
+		fmt.Fprint(w, `{"stat": 1}`)
+	}))
+	defer server.Close()
+
+	metrics := ejabberd.NewMetrics(0.5, 1)
+	client := ejabberd.Client{BaseURL: server.URL, Middleware: []ejabberd.Middleware{metrics.Middleware()}}
+	for i := 0; i < 2; i++ {
+		if _, err := client.Stats("onlineusers"); err != nil {
+			t.Fatalf("Stats failed: %s", err)
+		}
+	}
+	if _, err := client.RegisterUser("test@localhost", "passw0rd"); err == nil {
+		t.Fatalf("RegisterUser should have failed")
+	}
+
+	var buf bytes.Buffer
+	if _, err := metrics.WriteTo(&buf); err != nil {
+		t.Fatalf("WriteTo failed: %s", err)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		`ejabberd_api_requests_total{command="stats"} 2`,
+		`ejabberd_api_requests_total{command="register"} 1`,
+		`ejabberd_api_errors_total{command="register",type="api",status="409"} 1`,
+		`ejabberd_api_request_duration_seconds_bucket{command="stats",le="+Inf"} 2`,
+		`ejabberd_api_request_duration_seconds_count{command="stats"} 2`,
+		`ejabberd_api_in_flight_requests{command="stats"} 0`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Metrics do not contain %q:\n%s", want, out)
+		}
+	}
+}
+
+func Test_MetricsTransportError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	server.Close()
+
+	metrics := ejabberd.NewMetrics(0.5, 1)
+	client := ejabberd.Client{BaseURL: server.URL, Middleware: []ejabberd.Middleware{metrics.Middleware()}}
+	if _, err := client.Stats("onlineusers"); err == nil {
+		t.Fatalf("Stats should have failed")
+	}
+
+	var buf bytes.Buffer
+	if _, err := metrics.WriteTo(&buf); err != nil {
+		t.Fatalf("WriteTo failed: %s", err)
+	}
+	out := buf.String()
+	// Failed requests without response are observed in the latency
+	// histogram too.
+	for _, want := range []string{
+		`ejabberd_api_requests_total{command="stats"} 1`,
+		`ejabberd_api_errors_total{command="stats",type="transport",status="0"} 1`,
+		`ejabberd_api_request_duration_seconds_count{command="stats"} 1`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Metrics do not contain %q:\n%s", want, out)
+		}
+	}
+}
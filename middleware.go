@@ -0,0 +1,66 @@
+package ejabberd
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// APICall describes an attempt to call an ejabberd API command, as
+// seen by middlewares. Middlewares can change the call, for example to
+// add HTTP headers, before passing it to the next handler.
+type APICall struct {
+	Command string
+	Version int
+	Admin   bool
+
+	// Attempt is the attempt number, starting at 1. It is greater
+	// than 1 when the call is retried.
+	Attempt int
+
+	Method string
+	Query  url.Values
+	Header http.Header
+	Body   []byte
+}
+
+// APIResult is the outcome of an APICall, as seen by middlewares.
+type APIResult struct {
+	StatusCode int
+	Header     http.Header
+
+	// Body is the raw response body. It is nil when the response is
+	// streamed to the caller, see CallStream.
+	Body     []byte
+	Streamed bool
+
+	// Duration is the time spent to perform the HTTP request and to
+	// read the response. For streamed responses, it stops when
+	// response headers are received.
+	Duration time.Duration
+
+	stream io.ReadCloser
+}
+
+// CallHandler performs an APICall. When no response is received, it
+// returns a nil result and an error. When the response is received
+// but its body cannot be read, or is bigger than
+// Client.MaxResponseSize, it returns both the result, with status,
+// header and duration set and only the part of the body read so far,
+// and an error. Middlewares must check the result for nil before
+// using it.
+type CallHandler func(ctx context.Context, call *APICall) (*APIResult, error)
+
+// Middleware wraps a CallHandler to observe or change API calls and
+// their results. Middlewares are set on Client.Middleware, for
+// example to add a correlation header:
+//
+//	func correlationID(next ejabberd.CallHandler) ejabberd.CallHandler {
+//		return func(ctx context.Context, call *ejabberd.APICall) (*ejabberd.APIResult, error) {
+//			call.Header.Set("X-Correlation-ID", idFromContext(ctx))
+//			return next(ctx, call)
+//		}
+//	}
+type Middleware func(next CallHandler) CallHandler
@@ -0,0 +1,99 @@
+package ejabberd_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/processone/ejabberd-api"
+)
+
+func Test_Middleware(t *testing.T) {
+	var correlationID string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		correlationID = r.Header.Get("X-Correlation-ID")
+		fmt.Fprint(w, `"Success"`)
+	}))
+	defer server.Close()
+
+	var order []string
+	var seen ejabberd.APICall
+	var result ejabberd.APIResult
+	trace := func(name string) ejabberd.Middleware {
+		return func(next ejabberd.CallHandler) ejabberd.CallHandler {
+			return func(ctx context.Context, call *ejabberd.APICall) (*ejabberd.APIResult, error) {
+				order = append(order, name)
+				return next(ctx, call)
+			}
+		}
+	}
+	observe := func(next ejabberd.CallHandler) ejabberd.CallHandler {
+		return func(ctx context.Context, call *ejabberd.APICall) (*ejabberd.APIResult, error) {
+			call.Header.Set("X-Correlation-ID", "42")
+			res, err := next(ctx, call)
+			seen, result = *call, *res
+			return res, err
+		}
+	}
+
+	client := ejabberd.Client{
+		BaseURL:    server.URL,
+		Middleware: []ejabberd.Middleware{trace("outer"), observe, trace("inner")},
+	}
+	if _, err := client.RegisterUser("test@localhost", "passw0rd"); err != nil {
+		t.Fatalf("RegisterUser failed: %s", err)
+	}
+
+	if len(order) != 2 || order[0] != "outer" || order[1] != "inner" {
+		t.Errorf("Incorrect middleware order %v", order)
+	}
+	if correlationID != "42" {
+		t.Errorf("Incorrect correlation ID header %q", correlationID)
+	}
+	if seen.Command != "register" || !seen.Admin || len(seen.Body) == 0 {
+		t.Errorf("Incorrect call %+v", seen)
+	}
+	if result.StatusCode != 200 || string(result.Body) != `"Success"` {
+		t.Errorf("Incorrect result %+v", result)
+	}
+}
+
+func Test_MiddlewareResultOnError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"stat": 1234567890123456789}`)
+	}))
+
+	var res *ejabberd.APIResult
+	var err error
+	observe := func(next ejabberd.CallHandler) ejabberd.CallHandler {
+		return func(ctx context.Context, call *ejabberd.APICall) (*ejabberd.APIResult, error) {
+			res, err = next(ctx, call)
+			return res, err
+		}
+	}
+	client := ejabberd.Client{
+		BaseURL:         server.URL,
+		MaxResponseSize: 16,
+		Middleware:      []ejabberd.Middleware{observe},
+	}
+
+	// Response is received but too large: result is set with an error.
+	client.Stats("registeredusers")
+	var tooLarge ejabberd.ResponseTooLargeError
+	if !errors.As(err, &tooLarge) {
+		t.Errorf("Incorrect error %v, want ResponseTooLargeError", err)
+	}
+	if res == nil || res.StatusCode != 200 || len(res.Body) > 16 {
+		t.Errorf("Incorrect result %+v for too large response", res)
+	}
+
+	// No response: result is nil.
+	server.Close()
+	client.Stats("registeredusers")
+	if !errors.Is(err, ejabberd.ErrTransport) || res != nil {
+		t.Errorf("Incorrect result %+v, %v; want nil, transport error", res, err)
+	}
+}
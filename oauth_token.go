@@ -1,11 +1,12 @@
 package ejabberd
 
 import (
+	"context"
 	"encoding/json"
-	"errors"
 	"io/ioutil"
 	"net/http"
 	"net/url"
+	"strings"
 	"time"
 )
 
@@ -32,6 +33,16 @@ func (t OAuthToken) Save(file string) error {
 	return ioutil.WriteFile(file, b, 0640)
 }
 
+// Domain returns the domain part of the token JID, or an empty string
+// when the token JID is not a valid user JID.
+func (t OAuthToken) Domain() string {
+	j, err := parseJID(t.JID)
+	if err != nil {
+		return ""
+	}
+	return j.domain
+}
+
 // ReadOAuthToken reads the content of JSon OAuth token file and
 // return proper OAuthToken structure.
 func ReadOAuthToken(file string) (OAuthToken, error) {
@@ -50,28 +61,40 @@ func ReadOAuthToken(file string) (OAuthToken, error) {
 //==============================================================================
 // HTTP
 
-func httpGetToken(c *http.Client, apiURL string, params url.Values) (OAuthToken, error) {
-	// Performs HTTP request
-	resp, err := c.PostForm(apiURL, params)
+func httpGetToken(ctx context.Context, c *http.Client, apiURL string, params url.Values) (OAuthToken, error) {
+	req, err := http.NewRequestWithContext(ctx, "POST", apiURL, strings.NewReader(params.Encode()))
 	if err != nil {
 		return OAuthToken{}, err
 	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	if tp := traceParentFromContext(ctx); tp != "" {
+		req.Header.Set("Traceparent", tp)
+	}
+
+	// Performs HTTP request
+	resp, err := c.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return OAuthToken{}, ctx.Err()
+		}
+		return OAuthToken{}, TransportError{Command: "token", Err: err}
+	}
 	defer resp.Body.Close()
 
 	// Endpoint not found
 	if resp.StatusCode == 404 {
-		return OAuthToken{}, errors.New("oauth endpoint not found (404)")
+		return OAuthToken{}, TokenError{HTTPStatus: 404}
 	}
 
 	// Cannot read HTTP response
 	body, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
-		return OAuthToken{}, errors.New("cannot read HTTP response from server")
+		return OAuthToken{}, TransportError{Command: "token", Err: err}
 	}
 
 	// Bad request
-	if resp.StatusCode == 400 {
-		return OAuthToken{}, parseTokenError(body)
+	if resp.StatusCode == 400 || resp.StatusCode == 401 || resp.StatusCode == 403 {
+		return OAuthToken{}, parseTokenError(resp.StatusCode, body)
 	}
 
 	// Success
@@ -94,7 +117,7 @@ func tokenParams(j jid, password, scope, ttl string) url.Values {
 // ====
 // Process ejabberd HTTP token response
 
-func parseTokenError(body []byte) error {
+func parseTokenError(code int, body []byte) error {
 	type jsonError struct {
 		Error       string `json:"error"`
 		Description string `json:"error_description"`
@@ -102,9 +125,9 @@ func parseTokenError(body []byte) error {
 	var e jsonError
 
 	if err := json.Unmarshal(body, &e); err != nil {
-		return errors.New("bad request")
+		return TokenError{HTTPStatus: code}
 	}
-	return errors.New(e.Description)
+	return TokenError{HTTPStatus: code, Code: e.Error, Description: e.Description}
 }
 
 func parseTokenResponse(body []byte) (OAuthToken, error) {
@@ -117,7 +140,7 @@ func parseTokenResponse(body []byte) (OAuthToken, error) {
 	var r jsonResp
 
 	if err := json.Unmarshal(body, &r); err != nil {
-		return OAuthToken{}, err
+		return OAuthToken{}, DecodeError{Command: "token", Body: body, Err: err}
 	}
 
 	var t OAuthToken
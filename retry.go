@@ -0,0 +1,131 @@
+package ejabberd
+
+import (
+	"errors"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy defines how the client retries API calls that failed
+// with a transient error, like a network error or an HTTP 502 / 503
+// returned while an ejabberd node is restarting.
+//
+// Only read-only commands, like stats or user_resources, are retried
+// unless RetryWrites is set. Retrying a write command such as
+// register may apply it twice if the first attempt reached the
+// server.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts, including the
+	// first one. Values lower than 2 disable retries.
+	MaxAttempts int
+
+	// MinBackoff is the delay before the first retry. It doubles on
+	// each attempt, up to MaxBackoff. A random jitter is applied.
+	// Defaults to 100ms and 5s.
+	//
+	// A Retry-After header sent by the server replaces the computed
+	// delay. When it asks to wait longer than MaxBackoff, the call is
+	// not retried and the error is returned: retrying earlier would
+	// ignore the server request, and waiting longer would exceed the
+	// delay the caller allowed.
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+
+	// RetryableStatus lists the HTTP status codes that are retried.
+	// Defaults to 429, 502, 503 and 504.
+	RetryableStatus []int
+
+	// RetryWrites allows to retry commands that are not idempotent.
+	RetryWrites bool
+}
+
+var defaultRetryableStatus = []int{
+	http.StatusTooManyRequests,
+	http.StatusBadGateway,
+	http.StatusServiceUnavailable,
+	http.StatusGatewayTimeout,
+}
+
+// backoff returns whether the call must be retried after attempt, with
+// given result or error, and how long to wait before the next
+// attempt. It is safe to call on a nil policy.
+func (rp *RetryPolicy) backoff(attempt int, idempotent bool, res *APIResult, err error) (time.Duration, bool) {
+	if rp == nil || attempt >= rp.MaxAttempts {
+		return 0, false
+	}
+	if !idempotent && !rp.RetryWrites {
+		return 0, false
+	}
+
+	if err != nil && !errors.Is(err, ErrTransport) {
+		return 0, false
+	}
+
+	if err == nil {
+		if !rp.retryableStatus(res.StatusCode) {
+			return 0, false
+		}
+		if d, ok := retryAfter(res.Header.Get("Retry-After")); ok {
+			if d > rp.maxBackoff() {
+				return 0, false
+			}
+			return d, true
+		}
+	}
+
+	d := rp.minBackoff() << (attempt - 1)
+	if d <= 0 || d > rp.maxBackoff() {
+		d = rp.maxBackoff()
+	}
+	// Equal jitter: wait between half and full backoff duration.
+	d = d/2 + time.Duration(rand.Int63n(int64(d/2)+1))
+	return d, true
+}
+
+func (rp *RetryPolicy) retryableStatus(code int) bool {
+	status := rp.RetryableStatus
+	if status == nil {
+		status = defaultRetryableStatus
+	}
+	for _, s := range status {
+		if s == code {
+			return true
+		}
+	}
+	return false
+}
+
+func (rp *RetryPolicy) minBackoff() time.Duration {
+	if rp.MinBackoff <= 0 {
+		return 100 * time.Millisecond
+	}
+	return rp.MinBackoff
+}
+
+func (rp *RetryPolicy) maxBackoff() time.Duration {
+	if rp.MaxBackoff <= 0 {
+		return 5 * time.Second
+	}
+	return rp.MaxBackoff
+}
+
+// retryAfter parses Retry-After header value, expressed either in
+// seconds or as an HTTP date.
+func retryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(value); err == nil && secs >= 0 {
+		return time.Duration(secs) * time.Second, true
+	}
+	if t, err := http.ParseTime(value); err == nil {
+		d := time.Until(t)
+		if d < 0 {
+			d = 0
+		}
+		return d, true
+	}
+	return 0, false
+}
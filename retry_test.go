@@ -0,0 +1,82 @@
+package ejabberd_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/processone/ejabberd-api"
+)
+
+func Test_Retry(t *testing.T) {
+	var attempts int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&attempts, 1) < 3 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(503)
+			return
+		}
+		if r.URL.Path == "/api/register/v1" {
+			fmt.Fprintln(w, `"Success"`)
+			return
+		}
+		fmt.Fprintln(w, `{"stat": 1}`)
+	}))
+	defer server.Close()
+
+	retry := &ejabberd.RetryPolicy{MaxAttempts: 3, MinBackoff: time.Millisecond}
+	client := ejabberd.Client{BaseURL: server.URL, Retry: retry}
+
+	if _, err := client.Stats("registeredusers"); err != nil {
+		t.Errorf("Stats failed: %s", err)
+	}
+	if attempts != 3 {
+		t.Errorf("Incorrect number of attempts for stats %d != 3", attempts)
+	}
+
+	// Register is not idempotent and must not be retried by default.
+	atomic.StoreInt32(&attempts, 0)
+	if _, err := client.RegisterUser("test@localhost", "passw0rd"); err == nil {
+		t.Errorf("RegisterUser should have failed")
+	}
+	if attempts != 1 {
+		t.Errorf("Incorrect number of attempts for register %d != 1", attempts)
+	}
+
+	atomic.StoreInt32(&attempts, 0)
+	retry.RetryWrites = true
+	if _, err := client.RegisterUser("test@localhost", "passw0rd"); err != nil {
+		t.Errorf("RegisterUser failed: %s", err)
+	}
+	if attempts != 3 {
+		t.Errorf("Incorrect number of attempts for register %d != 3", attempts)
+	}
+}
+
+func Test_RetryAfterExceedsMaxBackoff(t *testing.T) {
+	var attempts int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&attempts, 1)
+		w.Header().Set("Retry-After", "120")
+		w.WriteHeader(503)
+	}))
+	defer server.Close()
+
+	retry := &ejabberd.RetryPolicy{MaxAttempts: 3, MaxBackoff: time.Second}
+	client := ejabberd.Client{BaseURL: server.URL, Retry: retry}
+
+	start := time.Now()
+	_, err := client.Stats("registeredusers")
+	if err == nil {
+		t.Errorf("Stats should have failed")
+	}
+	if attempts != 1 {
+		t.Errorf("Incorrect number of attempts %d != 1", attempts)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Stats waited %s for Retry-After", elapsed)
+	}
+}
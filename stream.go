@@ -0,0 +1,71 @@
+package ejabberd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// CallStream performs HTTP call to ejabberd API like CallRaw, but
+// does not buffer the response: the body is returned as a stream,
+// without size limit, and must be closed by the caller. If the server
+// does not reply with HTTP status 200, the body is read and returned
+// as an error.
+func (c Client) CallStream(body []byte, name string, admin bool) (io.ReadCloser, error) {
+	return c.CallStreamContext(context.Background(), body, name, admin)
+}
+
+// CallStreamContext is like CallStream but carries ctx into the HTTP
+// request. ctx must stay valid until the body has been read.
+func (c Client) CallStreamContext(ctx context.Context, body []byte, name string, admin bool) (io.ReadCloser, error) {
+	return c.stream(ctx, rawParams(name, c.APIVersion, body), admin)
+}
+
+// stream performs the HTTP request described by p and returns the
+// response body for incremental processing.
+func (c Client) stream(ctx context.Context, p apiParams, admin bool) (io.ReadCloser, error) {
+	ctx, finish := c.startSpan(ctx, p, admin)
+	res, err := c.exchange(ctx, p, admin, true)
+	if err != nil {
+		finish(0, err)
+		return nil, err
+	}
+
+	if res.StatusCode != 200 {
+		err = parseError(p.name, res.StatusCode, res.Body)
+		finish(res.StatusCode, err)
+		return nil, err
+	}
+
+	finish(res.StatusCode, nil)
+	return res.stream, nil
+}
+
+// DecodeArray reads a JSON array from r, typically a stream returned
+// by CallStream, and calls fn for each element, without loading the
+// whole array in memory. It stops on the first error returned by fn.
+func DecodeArray(r io.Reader, fn func(json.RawMessage) error) error {
+	dec := json.NewDecoder(r)
+
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if delim, ok := tok.(json.Delim); !ok || delim != '[' {
+		return fmt.Errorf("expected JSON array, got %v", tok)
+	}
+
+	for dec.More() {
+		var elt json.RawMessage
+		if err := dec.Decode(&elt); err != nil {
+			return err
+		}
+		if err := fn(elt); err != nil {
+			return err
+		}
+	}
+
+	_, err = dec.Token()
+	return err
+}
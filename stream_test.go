@@ -0,0 +1,64 @@
+package ejabberd_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/processone/ejabberd-api"
+)
+
+func Test_CallStream(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, `["user1@localhost", "user2@localhost", "user3@localhost"]`)
+	}))
+	defer server.Close()
+
+	client := ejabberd.Client{BaseURL: server.URL, MaxResponseSize: 16}
+
+	_, _, err := client.CallRaw([]byte(`{"host":"localhost"}`), "registered_users", true)
+	var tooLarge ejabberd.ResponseTooLargeError
+	if !errors.As(err, &tooLarge) || tooLarge.Limit != 16 {
+		t.Errorf("CallRaw error = %v, want ResponseTooLargeError", err)
+	}
+
+	body, err := client.CallStream([]byte(`{"host":"localhost"}`), "registered_users", true)
+	if err != nil {
+		t.Fatalf("CallStream failed: %s", err)
+	}
+	defer body.Close()
+
+	var users []string
+	err = ejabberd.DecodeArray(body, func(elt json.RawMessage) error {
+		var user string
+		if err := json.Unmarshal(elt, &user); err != nil {
+			return err
+		}
+		users = append(users, user)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("DecodeArray failed: %s", err)
+	}
+	if len(users) != 3 || users[2] != "user3@localhost" {
+		t.Errorf("Incorrect users %v", users)
+	}
+}
+
+func Test_CallStreamError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(404)
+		fmt.Fprintln(w, `{"status": "error", "code": 1, "message": "Unknown command"}`)
+	}))
+	defer server.Close()
+
+	client := ejabberd.Client{BaseURL: server.URL}
+	_, err := client.CallStream(nil, "unknown", false)
+	var apiError ejabberd.APIError
+	if !errors.As(err, &apiError) || apiError.Message != "Unknown command" {
+		t.Errorf("CallStream error = %v, want APIError", err)
+	}
+}
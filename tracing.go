@@ -0,0 +1,136 @@
+package ejabberd
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+)
+
+// Span is the part of a tracing span used by the client. It is
+// modelled after OpenTelemetry spans, so that an OpenTelemetry span
+// can be adapted with a thin wrapper.
+type Span interface {
+	SetAttribute(key string, value interface{})
+	RecordError(err error)
+	End()
+
+	// TraceParent returns the W3C traceparent header value
+	// identifying the span, or an empty string to disable
+	// propagation. See FormatTraceParent.
+	TraceParent() string
+}
+
+// Tracer starts spans. Set it on Client.Tracer to get a span for each
+// ejabberd command, named "ejabberd <command>", with the following
+// attributes:
+//
+//	ejabberd.command   Name of the command.
+//	ejabberd.vhost     Virtual host, when the command has one.
+//	ejabberd.admin     Whether the command is called as admin.
+//	http.status_code   HTTP status code of the response.
+//	error.type         api, decode, transport, canceled, too_large or other.
+//
+// The span context is propagated to ejabberd in the traceparent HTTP
+// header.
+type Tracer interface {
+	Start(ctx context.Context, name string) (context.Context, Span)
+}
+
+// FormatTraceParent formats a W3C traceparent header value from trace
+// and span identifiers. It matches OpenTelemetry TraceID and SpanID
+// types, so that an adapter can be written as:
+//
+//	func (s otelSpan) TraceParent() string {
+//		sc := s.Span.SpanContext()
+//		return ejabberd.FormatTraceParent(sc.TraceID(), sc.SpanID(), sc.IsSampled())
+//	}
+func FormatTraceParent(traceID [16]byte, spanID [8]byte, sampled bool) string {
+	if traceID == [16]byte{} || spanID == [8]byte{} {
+		return ""
+	}
+	flags := "00"
+	if sampled {
+		flags = "01"
+	}
+	return "00-" + hex.EncodeToString(traceID[:]) + "-" + hex.EncodeToString(spanID[:]) + "-" + flags
+}
+
+type traceParentKey struct{}
+
+func traceParentFromContext(ctx context.Context) string {
+	tp, _ := ctx.Value(traceParentKey{}).(string)
+	return tp
+}
+
+// startSpan starts a span for command p, if the client has a tracer.
+// The returned function ends the span, given the HTTP status code and
+// the call error.
+func (c Client) startSpan(ctx context.Context, p apiParams, admin bool) (context.Context, func(int, error)) {
+	if c.Tracer == nil {
+		return ctx, func(int, error) {}
+	}
+
+	ctx, span := c.Tracer.Start(ctx, "ejabberd "+p.name)
+	span.SetAttribute("ejabberd.command", p.name)
+	span.SetAttribute("ejabberd.admin", admin)
+	if vhost := vhostFromParams(p); vhost != "" {
+		span.SetAttribute("ejabberd.vhost", vhost)
+	}
+	if tp := span.TraceParent(); tp != "" {
+		ctx = context.WithValue(ctx, traceParentKey{}, tp)
+	}
+
+	return ctx, func(code int, err error) {
+		if code != 0 {
+			span.SetAttribute("http.status_code", code)
+		}
+		if err != nil {
+			span.SetAttribute("error.type", errorKind(err))
+			span.RecordError(err)
+		}
+		span.End()
+	}
+}
+
+// startTokenSpan starts a span for a token request, if the client has
+// a tracer. The returned function ends the span.
+func (c Client) startTokenSpan(ctx context.Context, j jid) (context.Context, func(error)) {
+	if c.Tracer == nil {
+		return ctx, func(error) {}
+	}
+
+	ctx, span := c.Tracer.Start(ctx, "ejabberd token")
+	span.SetAttribute("ejabberd.vhost", j.domain)
+	if tp := span.TraceParent(); tp != "" {
+		ctx = context.WithValue(ctx, traceParentKey{}, tp)
+	}
+
+	return ctx, func(err error) {
+		if err != nil {
+			span.SetAttribute("error.type", errorKind(err))
+			span.RecordError(err)
+		}
+		span.End()
+	}
+}
+
+// vhostFromParams extracts the virtual host a command applies to, from
+// its host or server argument.
+func vhostFromParams(p apiParams) string {
+	for _, key := range []string{"host", "server"} {
+		if v := p.query.Get(key); v != "" {
+			return v
+		}
+	}
+
+	var args map[string]interface{}
+	if err := json.Unmarshal(p.body, &args); err != nil {
+		return ""
+	}
+	for _, key := range []string{"host", "server"} {
+		if v, ok := args[key].(string); ok {
+			return v
+		}
+	}
+	return ""
+}
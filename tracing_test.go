@@ -0,0 +1,78 @@
+package ejabberd_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/processone/ejabberd-api"
+)
+
+type testSpan struct {
+	name  string
+	attrs map[string]interface{}
+	err   error
+	ended bool
+}
+
+func (s *testSpan) SetAttribute(key string, value interface{}) { s.attrs[key] = value }
+func (s *testSpan) RecordError(err error)                      { s.err = err }
+func (s *testSpan) End()                                       { s.ended = true }
+
+func (s *testSpan) TraceParent() string {
+	traceID := [16]byte{0x4b, 0xf9, 0x2f, 0x35, 0x77, 0xb3, 0x4d, 0xa6, 0xa3, 0xce, 0x92, 0x9d, 0x0e, 0x0e, 0x47, 0x36}
+	spanID := [8]byte{0x00, 0xf0, 0x67, 0xaa, 0x0b, 0xa9, 0x02, 0xb7}
+	return ejabberd.FormatTraceParent(traceID, spanID, true)
+}
+
+type testTracer struct {
+	spans []*testSpan
+}
+
+func (t *testTracer) Start(ctx context.Context, name string) (context.Context, ejabberd.Span) {
+	span := &testSpan{name: name, attrs: make(map[string]interface{})}
+	t.spans = append(t.spans, span)
+	return ctx, span
+}
+
+func Test_Tracer(t *testing.T) {
+	var traceParent string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceParent = r.Header.Get("Traceparent")
+		w.WriteHeader(409)
+		fmt.Fprint(w, `{"status":"error","code":10090,"message":"User already registered"}`)
+	}))
+	defer server.Close()
+
+	tracer := &testTracer{}
+	client := ejabberd.Client{BaseURL: server.URL, Tracer: tracer}
+	if _, err := client.RegisterUser("test@localhost", "passw0rd"); !errors.Is(err, ejabberd.ErrConflict) {
+		t.Fatalf("RegisterUser error %v does not match %v", err, ejabberd.ErrConflict)
+	}
+
+	if traceParent != "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01" {
+		t.Errorf("Incorrect traceparent header %q", traceParent)
+	}
+	if len(tracer.spans) != 1 {
+		t.Fatalf("Incorrect number of spans %d != 1", len(tracer.spans))
+	}
+	span := tracer.spans[0]
+	if span.name != "ejabberd register" || !span.ended || span.err == nil {
+		t.Errorf("Incorrect span %+v", span)
+	}
+	want := map[string]interface{}{
+		"ejabberd.command": "register",
+		"ejabberd.vhost":   "localhost",
+		"ejabberd.admin":   true,
+		"http.status_code": 409,
+		"error.type":       "api",
+	}
+	for key, value := range want {
+		if span.attrs[key] != value {
+			t.Errorf("Incorrect span attribute %s = %v, want %v", key, span.attrs[key], value)
+		}
+	}
+}
@@ -44,8 +44,10 @@ func tokenURL(baseURL, oauthPath string) (string, error) {
 }
 
 // apiURL generates URL endpoint for calling a given ejabberd API
-// command name.
-func apiURL(baseURL, apiPath, name string) (string, error) {
+// command name. When version is strictly positive, it is appended to
+// the command path (for example api/register/v1), which is how
+// mod_http_api selects the version of the command to run.
+func apiURL(baseURL, apiPath, name string, version int) (string, error) {
 	var path string
 	var err error
 
@@ -59,5 +61,8 @@ func apiURL(baseURL, apiPath, name string) (string, error) {
 		return baseURL, err
 	}
 
+	if version > 0 {
+		return joinURL(path, fmt.Sprintf("%s/v%d", name, version))
+	}
 	return joinURL(path, name+"/")
 }